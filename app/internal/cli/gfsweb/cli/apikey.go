@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey/apikeycmd"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/audit"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb"
+)
+
+const (
+	//SubcommandIssueAPIKey is the subcommand that issues a new api key and prints
+	//its token, e.g. to create the first key needed to use the API.
+	SubcommandIssueAPIKey = "issue-api-key"
+
+	//cliActor is the audit actor of commands executed by subcommands.
+	cliActor = "cli"
+)
+
+//errIssueAPIKeyUsage is returned if the issue-api-key subcommand is not given
+//a name.
+var errIssueAPIKeyUsage = errors.New("usage: gfsweb " + SubcommandIssueAPIKey + " <name>")
+
+//errIssueAPIKeyMemory is returned if the issue-api-key subcommand is run with the
+//memory storage driver, since the key would be lost when the subcommand exits.
+var errIssueAPIKeyMemory = errors.New(
+	"gfsweb: " + SubcommandIssueAPIKey + " cannot store keys with the " + gfsweb.StorageDriverMemory +
+		" storage driver; disable auth for it instead",
+)
+
+//issueAPIKey issues a new api key named by args and writes its token to out.
+//The application's configuration determines where the key is stored, and it is an
+//error if keys would only be stored in memory.
+func issueAPIKey(ctx context.Context, args []string, out io.Writer) error {
+	name := strings.TrimSpace(strings.Join(args, " "))
+	if name == "" {
+		return errIssueAPIKeyUsage
+	}
+
+	c, err := newConfig()
+	if err != nil {
+		return err
+	}
+	if c.GetString(gfsweb.ConfigKeyStorageDriver) == gfsweb.StorageDriverMemory {
+		return errIssueAPIKeyMemory
+	}
+
+	bus, closer, err := gfsweb.NewAppBuilder().BuildBus(c)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	ctx = audit.ContextWithActor(ctx, cliActor)
+
+	result, err := bus.ExecuteContext(ctx, &apikeycmd.IssueKeyCommand{Name: name})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, result.(*apikey.Key).Token)
+	return err
+}
@@ -10,8 +10,20 @@ import (
 
 //Main is the the entrypoint client code can use to invoke gfsweb as if
 //it were entered through the command line.
-func Main(ctx context.Context, _ []string, _ io.Reader, _, outErr io.Writer) int {
-	err := main(ctx)
+//
+//Without args the application is run.
+//Otherwise the first of args names the subcommand to run instead.
+func Main(ctx context.Context, args []string, _ io.Reader, out, outErr io.Writer) int {
+	var err error
+
+	switch {
+	case len(args) == 0:
+		err = main(ctx)
+	case args[0] == SubcommandIssueAPIKey:
+		err = issueAPIKey(ctx, args[1:], out)
+	default:
+		err = fmt.Errorf("gfsweb: unknown subcommand %q", args[0])
+	}
 
 	if err != nil {
 		fmt.Fprintln(outErr, err)
@@ -0,0 +1,113 @@
+package apikeycmd
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey"
+	"github.com/gogolfing/cbus"
+)
+
+//Handler is a handler type that understands how to work with Key commands.
+type Handler struct {
+	//Keys is the Key repository to use within Command handling.
+	Keys apikey.Repo
+}
+
+//IssueKey attempts to create a new Key and add it to h.Keys.
+//Cmd must be of type *IssueKeyCommand.
+//The result, if not nil and without error, will be a *apikey.Key whose Token is
+//set.
+//This is the only time the Token is available.
+//apikey.ErrEmptyName is returned if the command's Name is empty.
+func (h *Handler) IssueKey(ctx context.Context, cmd cbus.Command) (interface{}, error) {
+	issueKey := cmd.(*IssueKeyCommand)
+
+	k, err := issueKey.newKey()
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.Keys.Add(ctx, *k)
+
+	return k, err
+}
+
+//IssueKeyCommand is a Command that should be used to issue a new Key.
+type IssueKeyCommand struct {
+	//Name is the new Key's Name.
+	Name string
+}
+
+func (c *IssueKeyCommand) newKey() (*apikey.Key, error) {
+	name := strings.TrimSpace(c.Name)
+	if name == "" {
+		return nil, apikey.ErrEmptyName
+	}
+
+	id, err := data.NewId()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := apikey.NewToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return &apikey.Key{
+		Id:        id,
+		Name:      name,
+		Prefix:    apikey.Prefix(token),
+		Hash:      apikey.Hash(token),
+		Token:     token,
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		Version:   1,
+	}, nil
+}
+
+//RevokeKey attempts to revoke a Key in h.Keys so that it can no longer be used.
+//Cmd must be of type *RevokeKeyCommand.
+//The result, if not nil and without error, will be a *apikey.Key.
+//A *data.NotFoundError is returned if the Key does not exist.
+//A *data.VersionMismatchError is returned if the Key is not at the command's
+//Version or if it is changed concurrently.
+//
+//Revoking a Key that is already revoked changes nothing.
+func (h *Handler) RevokeKey(ctx context.Context, cmd cbus.Command) (interface{}, error) {
+	revokeKey := cmd.(*RevokeKeyCommand)
+
+	k, err := h.Keys.Get(ctx, revokeKey.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if revokeKey.Version != nil && k.Version != *revokeKey.Version {
+		return nil, data.NewVersionMismatchError(apikey.EntityName, k.Id)
+	}
+
+	if k.Revoked() {
+		return k, nil
+	}
+
+	revokedAt := time.Now().UTC().Truncate(time.Microsecond)
+	k.RevokedAt = &revokedAt
+
+	if err := h.Keys.Set(ctx, *k); err != nil {
+		return nil, err
+	}
+	k.Version++
+
+	return k, nil
+}
+
+//RevokeKeyCommand is a Command to revoke a Key.
+type RevokeKeyCommand struct {
+	//Id is the Id of the Key to revoke.
+	Id data.Id
+
+	//Version, if not nil, is the Version the Key must be at to be revoked.
+	Version *int
+}
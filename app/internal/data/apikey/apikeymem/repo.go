@@ -0,0 +1,117 @@
+package apikeymem
+
+import (
+	"context"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/memrepo"
+)
+
+var _ apikey.Repo = &Repo{} //Ensure *Repo is an apikey.Repo.
+
+const (
+	//Table is the memrepo table we store our api key entities in.
+	Table = "api_keys"
+)
+
+//Repo is an apikey.Repo implementation that stores Keys in memory.
+type Repo struct {
+	mem *memrepo.Repo
+}
+
+//New returns a new Repo that uses repo as its storage.
+func New(repo *memrepo.Repo) *Repo {
+	return &Repo{
+		mem: repo,
+	}
+}
+
+//Get is the apikey.QueryRepo implementation.
+func (r *Repo) Get(ctx context.Context, id data.Id) (*apikey.Key, error) {
+	var result *apikey.Key
+
+	err := r.mem.ReadWorkContext(ctx, func(tx *memrepo.Tx) error {
+		row, ok := tx.Get(Table, id)
+		if !ok {
+			return data.NewNotFoundError(apikey.EntityName, "id", id)
+		}
+
+		result = fromRow(row)
+		return nil
+	})
+
+	return result, err
+}
+
+//GetHash is the apikey.QueryRepo implementation.
+func (r *Repo) GetHash(ctx context.Context, hash string) (*apikey.Key, error) {
+	var result *apikey.Key
+
+	err := r.mem.ReadWorkContext(ctx, func(tx *memrepo.Tx) error {
+		result = findHash(tx, hash)
+		if result == nil {
+			return data.NewNotFoundError(apikey.EntityName, "hash", hash)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+//findHash is a helper function to return the Key in tx with hash, or nil if
+//there is none.
+func findHash(tx *memrepo.Tx, hash string) *apikey.Key {
+	for _, row := range tx.Rows(Table) {
+		if k := fromRow(row); k.Hash == hash {
+			return k
+		}
+	}
+	return nil
+}
+
+//fromRow is a helper function to return a new Key copied from a stored row.
+func fromRow(row interface{}) *apikey.Key {
+	k := row.(apikey.Key)
+	if k.RevokedAt != nil {
+		revokedAt := *k.RevokedAt
+		k.RevokedAt = &revokedAt
+	}
+	return &k
+}
+
+//Add is the apikey.Repo implementation.
+//The Key's Token is not stored.
+func (r *Repo) Add(ctx context.Context, k apikey.Key) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		if _, ok := tx.Get(Table, k.Id); ok {
+			return data.NewConflictError(apikey.EntityName, "id")
+		}
+		if findHash(tx, k.Hash) != nil {
+			return data.NewConflictError(apikey.EntityName, "hash")
+		}
+
+		k.Token = ""
+		tx.Put(Table, k.Id, *fromRow(k))
+		return nil
+	})
+}
+
+//Set is the apikey.Repo implementation.
+//The Key's Token is not stored.
+func (r *Repo) Set(ctx context.Context, k apikey.Key) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		row, ok := tx.Get(Table, k.Id)
+		if !ok {
+			return data.NewNotFoundError(apikey.EntityName, "id", k.Id)
+		}
+		if row.(apikey.Key).Version != k.Version {
+			return data.NewVersionMismatchError(apikey.EntityName, k.Id)
+		}
+
+		k.Token = ""
+		k.Version++
+		tx.Put(Table, k.Id, *fromRow(k))
+		return nil
+	})
+}
@@ -0,0 +1,166 @@
+package apikeysql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/sqlrepo"
+)
+
+var _ apikey.Repo = &Repo{} //Ensure *Repo is an apikey.Repo.
+
+const (
+	//Table is the table we query from to get our api key entities.
+	Table = "api_keys"
+)
+
+//SelectFrom is our select query without filtering, ordering, etc.
+const SelectFrom = `SELECT
+	k.id,
+	k.name,
+	k.prefix,
+	k.hash,
+	k.created_at,
+	k.revoked_at,
+	k.version
+	FROM ` + Table + ` AS k`
+
+//Repo is an apikey.Repo implementation that uses a SQL database as its storage.
+type Repo struct {
+	db *sqlrepo.Repo
+}
+
+//New returns a new Repo that uses repo to talk to the database.
+func New(repo *sqlrepo.Repo) *Repo {
+	return &Repo{
+		db: repo,
+	}
+}
+
+//Get is the apikey.QueryRepo implementation.
+func (r *Repo) Get(ctx context.Context, id data.Id) (*apikey.Key, error) {
+	row := r.db.QueryRowContext(ctx, SelectFrom+" WHERE k.id = ?", id)
+
+	k, err := scan(row)
+	if err != nil {
+		return nil, sqlrepo.NoRows(err, data.NewNotFoundError(apikey.EntityName, "id", id))
+	}
+
+	return k, nil
+}
+
+//GetHash is the apikey.QueryRepo implementation.
+func (r *Repo) GetHash(ctx context.Context, hash string) (*apikey.Key, error) {
+	row := r.db.QueryRowContext(ctx, SelectFrom+" WHERE k.hash = ?", hash)
+
+	k, err := scan(row)
+	if err != nil {
+		return nil, sqlrepo.NoRows(err, data.NewNotFoundError(apikey.EntityName, "hash", hash))
+	}
+
+	return k, nil
+}
+
+//scan is a helper function to scan a single Key from a sql row or rows.
+func scan(s sqlrepo.Scanner) (*apikey.Key, error) {
+	k := &apikey.Key{}
+
+	createdAt, revokedAt := sqlrepo.UTCTime{}, sqlrepo.NullUTCTime{}
+
+	if err := s.Scan(
+		&k.Id,
+		&k.Name,
+		&k.Prefix,
+		&k.Hash,
+		&createdAt,
+		&revokedAt,
+		&k.Version,
+	); err != nil {
+		return nil, err
+	}
+
+	k.CreatedAt = createdAt.Time
+	if revokedAt.Valid {
+		k.RevokedAt = &revokedAt.Time
+	}
+
+	return k, nil
+}
+
+//Add is the apikey.Repo implementation.
+func (r *Repo) Add(ctx context.Context, k apikey.Key) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			`INSERT INTO %s (
+				id,
+				name,
+				prefix,
+				hash,
+				created_at,
+				revoked_at,
+				version
+			) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			Table,
+		),
+		k.Id,
+		k.Name,
+		k.Prefix,
+		k.Hash,
+		sqlrepo.UTCTime{k.CreatedAt},
+		nullTime(k.RevokedAt),
+		k.Version,
+	)
+
+	return r.db.TranslateError(err, apikey.EntityName)
+}
+
+//Set is the apikey.Repo implementation.
+func (r *Repo) Set(ctx context.Context, k apikey.Key) error {
+	work := func(qec sqlrepo.QueryExecerContext) error {
+		result, err := qec.ExecContext(
+			ctx,
+			fmt.Sprintf(
+				`UPDATE %s SET
+					name = ?,
+					prefix = ?,
+					hash = ?,
+					created_at = ?,
+					revoked_at = ?,
+					version = version + 1
+					WHERE id = ? AND version = ?`,
+				Table,
+			),
+			k.Name,
+			k.Prefix,
+			k.Hash,
+			sqlrepo.UTCTime{k.CreatedAt},
+			nullTime(k.RevokedAt),
+			k.Id,
+			k.Version,
+		)
+		return sqlrepo.RequireVersionedUpdate(
+			ctx,
+			qec,
+			result,
+			err,
+			Table,
+			k.Id,
+			data.NewNotFoundError(apikey.EntityName, "id", k.Id),
+			data.NewVersionMismatchError(apikey.EntityName, k.Id),
+		)
+	}
+
+	return r.db.TranslateError(r.db.TxWorkContext(ctx, work), apikey.EntityName)
+}
+
+//nullTime is a helper function to return t as a sqlrepo.NullUTCTime.
+func nullTime(t *time.Time) sqlrepo.NullUTCTime {
+	if t == nil {
+		return sqlrepo.NullUTCTime{}
+	}
+	return sqlrepo.NullUTCTime{Time: *t, Valid: true}
+}
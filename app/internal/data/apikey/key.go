@@ -0,0 +1,56 @@
+package apikey
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//EntityName is the name used to refer to Key entities in errors and other
+//descriptions.
+const EntityName = "api key"
+
+//ErrEmptyName is a sentinel error indicating that a Key is being issued without
+//a Name.
+var ErrEmptyName = errors.New("apikey: name must not be empty")
+
+//Key is a domain type that allows requests bearing its token to use the API.
+type Key struct {
+	//Id is the Key's id.
+	Id data.Id
+
+	//Name describes who or what the Key was issued to.
+	Name string
+
+	//Prefix is the beginning of the Key's token, which identifies the Key to
+	//people without revealing the token.
+	Prefix string
+
+	//Hash is the result of Hash for the Key's token.
+	//Tokens themselves are never stored.
+	//It is never marshaled so that it does not leak into logs.
+	Hash string `json:"-"`
+
+	//Token is the Key's token.
+	//It is only set on the Key returned when it is issued since it cannot be
+	//retrieved afterwards.
+	//It is never marshaled so that it does not leak into logs.
+	Token string `json:"-"`
+
+	//CreatedAt is the time at which the Key was issued.
+	CreatedAt time.Time
+
+	//RevokedAt is the time at which the Key was revoked, or nil if it has not
+	//been revoked.
+	RevokedAt *time.Time
+
+	//Version is incremented every time the Key is updated.
+	//It starts at 1.
+	Version int
+}
+
+//Revoked returns whether or not k has been revoked and can no longer be used.
+func (k *Key) Revoked() bool {
+	return k.RevokedAt != nil
+}
@@ -0,0 +1,45 @@
+package apikey
+
+import (
+	"context"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//Principal identifies the Key that authenticated a request.
+type Principal struct {
+	//KeyId is the Id of the Key.
+	KeyId data.Id
+
+	//Name is the Name of the Key.
+	Name string
+}
+
+//NewPrincipal returns the Principal authenticated by k.
+func NewPrincipal(k *Key) Principal {
+	return Principal{
+		KeyId: k.Id,
+		Name:  k.Name,
+	}
+}
+
+//String returns p as an audit actor, e.g. "api_key:<id>".
+func (p Principal) String() string {
+	return "api_key:" + p.KeyId.String()
+}
+
+//principalKey is the context key for the Principal value.
+type principalKey struct{}
+
+//ContextWithPrincipal returns a copy of ctx that records p as the authenticated
+//Principal.
+func ContextWithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+//PrincipalFromContext returns the Principal stored in ctx by ContextWithPrincipal
+//and whether or not there is one.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
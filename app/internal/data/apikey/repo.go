@@ -0,0 +1,40 @@
+package apikey
+
+import (
+	"context"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//QueryRepo provides methods for retrieving Keys.
+type QueryRepo interface {
+	//Get should return the Key whose Id equals id.
+	//
+	//A *data.NotFoundError should be returned if the Key does not exist.
+	Get(ctx context.Context, id data.Id) (*Key, error)
+
+	//GetHash should return the Key whose Hash equals hash.
+	//
+	//A *data.NotFoundError should be returned if the Key does not exist.
+	GetHash(ctx context.Context, hash string) (*Key, error)
+}
+
+//Repo provides methods for creating and updating Keys as well as promotes the
+//QueryRepo interface.
+type Repo interface {
+	//QueryRepo is promoted here to indicate a Repo contains all query methods.
+	QueryRepo
+
+	//Add should add k to the underlying storage repository.
+	//A *data.ConflictError should be returned if k's Hash is already taken.
+	Add(ctx context.Context, k Key) error
+
+	//Set should update all stored fields of k in the underlying storage repository.
+	//The update should use k.Id for determining which entity to update.
+	//
+	//The update should only happen if the stored version equals k.Version, and it
+	//should increment the stored version by one.
+	//A *data.VersionMismatchError should be returned if the stored version differs,
+	//and a *data.NotFoundError should be returned if the Key does not exist.
+	Set(ctx context.Context, k Key) error
+}
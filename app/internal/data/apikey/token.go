@@ -0,0 +1,42 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+//tokenPrefix starts every token so that leaked tokens are easy to recognize.
+const tokenPrefix = "gfs_"
+
+//tokenSize is the number of random bytes in a token from NewToken.
+const tokenSize = 32
+
+//prefixLength is the length of the Prefix of a Key, including tokenPrefix.
+const prefixLength = len(tokenPrefix) + 8
+
+//NewToken returns a new random token for a Key.
+func NewToken() (string, error) {
+	p := make([]byte, tokenSize)
+	if _, err := rand.Read(p); err != nil {
+		return "", err
+	}
+	return tokenPrefix + hex.EncodeToString(p), nil
+}
+
+//Hash returns the hex encoded SHA-256 of token.
+//Tokens are random, so they do not need a slow or salted hash.
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+//Prefix returns the Prefix of the Key with token.
+//An empty string is returned if token is not in the format returned by NewToken.
+func Prefix(token string) string {
+	if !strings.HasPrefix(token, tokenPrefix) || len(token) < prefixLength {
+		return ""
+	}
+	return token[:prefixLength]
+}
@@ -0,0 +1,26 @@
+package audit
+
+import "context"
+
+//Anonymous is the actor recorded for commands executed without an actor in
+//their context.
+const Anonymous = "anonymous"
+
+//actorKey is the context key for the actor value.
+type actorKey struct{}
+
+//ContextWithActor returns a copy of ctx that records actor as the one executing
+//commands.
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+//ActorFromContext returns the actor stored in ctx by ContextWithActor.
+//Anonymous is returned if there is none.
+func ActorFromContext(ctx context.Context) string {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	if !ok || actor == "" {
+		return Anonymous
+	}
+	return actor
+}
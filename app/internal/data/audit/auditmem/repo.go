@@ -0,0 +1,85 @@
+package auditmem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/audit"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/memrepo"
+)
+
+var _ audit.Repo = &Repo{} //Ensure *Repo is an audit.Repo.
+
+//Table is the memrepo table we store our audit entries in.
+const Table = "audit_log"
+
+//Repo is an audit.Repo implementation that stores Entries in memory.
+type Repo struct {
+	mem *memrepo.Repo
+}
+
+//New returns a new Repo that uses repo as its storage.
+func New(repo *memrepo.Repo) *Repo {
+	return &Repo{
+		mem: repo,
+	}
+}
+
+//ListPage is the audit.QueryRepo implementation.
+func (r *Repo) ListPage(ctx context.Context, criteria audit.Criteria, page data.PageRequest) ([]*audit.Entry, data.Page, error) {
+	after, err := data.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+	if after != "" {
+		if _, _, err := audit.ParseKey(after); err != nil {
+			return nil, data.Page{}, err
+		}
+	}
+
+	entries := []*audit.Entry{}
+	err = r.mem.ReadWorkContext(ctx, func(tx *memrepo.Tx) error {
+		for _, row := range tx.Rows(Table) {
+			e := row.(audit.Entry)
+			if criteria.Matches(&e) {
+				entries = append(entries, &e)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return audit.Key(entries[i]) < audit.Key(entries[j])
+	})
+
+	total := len(entries)
+	start := sort.Search(total, func(i int) bool {
+		return audit.Key(entries[i]) > after
+	})
+	entries = entries[start:]
+	if len(entries) > page.Limit+1 {
+		entries = entries[:page.Limit+1]
+	}
+
+	n, result := page.Paginate(len(entries), total, func(i int) string {
+		return audit.Key(entries[i])
+	})
+
+	return entries[:n], result, nil
+}
+
+//Add is the audit.Repo implementation.
+func (r *Repo) Add(ctx context.Context, e audit.Entry) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		if _, ok := tx.Get(Table, e.Id); ok {
+			return data.NewConflictError(audit.EntityName, "id")
+		}
+
+		tx.Put(Table, e.Id, e)
+		return nil
+	})
+}
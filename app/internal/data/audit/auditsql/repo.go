@@ -0,0 +1,198 @@
+package auditsql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/audit"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/sqlrepo"
+)
+
+var _ audit.Repo = &Repo{} //Ensure *Repo is an audit.Repo.
+
+//Table is the table we query from to get our audit entries.
+const Table = "audit_log"
+
+//SelectFrom is our select query without filtering, ordering, etc.
+const SelectFrom = `SELECT
+	a.id,
+	a.actor,
+	a.command,
+	a.payload,
+	a.entity,
+	a.entity_id,
+	a.snapshot_before,
+	a.snapshot_after,
+	a.created_at
+	FROM ` + Table + ` AS a`
+
+//CountFrom is our count query without filtering.
+const CountFrom = `SELECT COUNT(*) FROM ` + Table + ` AS a`
+
+//Repo is an audit.Repo implementation that uses a SQL database as its storage.
+type Repo struct {
+	db *sqlrepo.Repo
+}
+
+//New returns a new Repo that uses repo to talk to the database.
+func New(repo *sqlrepo.Repo) *Repo {
+	return &Repo{
+		db: repo,
+	}
+}
+
+//ListPage is the audit.QueryRepo implementation.
+func (r *Repo) ListPage(ctx context.Context, criteria audit.Criteria, page data.PageRequest) ([]*audit.Entry, data.Page, error) {
+	after, err := data.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	conditions, args := criteriaConditions(criteria)
+
+	total := 0
+	err = r.db.QueryRowContext(
+		ctx,
+		CountFrom+sqlrepo.Where(conditions...),
+		args...,
+	).Scan(&total)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	if after != "" {
+		createdAt, id, err := audit.ParseKey(after)
+		if err != nil {
+			return nil, data.Page{}, err
+		}
+
+		conditions = append(conditions, "(a.created_at > ? OR (a.created_at = ? AND a.id > ?))")
+		args = append(args, sqlrepo.UTCTime{createdAt}, sqlrepo.UTCTime{createdAt}, id)
+	}
+	query := SelectFrom + sqlrepo.Where(conditions...) + " ORDER BY a.created_at ASC, a.id ASC LIMIT ?"
+	args = append(args, page.Limit+1)
+
+	entries, err := r.list(ctx, query, args...)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	n, result := page.Paginate(len(entries), total, func(i int) string {
+		return audit.Key(entries[i])
+	})
+
+	return entries[:n], result, nil
+}
+
+//criteriaConditions is a helper function to translate criteria into query
+//conditions and their arguments.
+func criteriaConditions(criteria audit.Criteria) ([]string, []interface{}) {
+	conditions, args := []string{}, []interface{}{}
+
+	if criteria.EntityId != nil {
+		conditions = append(conditions, "a.entity_id = ?")
+		args = append(args, *criteria.EntityId)
+	}
+
+	if criteria.Since != nil {
+		conditions = append(conditions, "a.created_at >= ?")
+		args = append(args, sqlrepo.UTCTime{*criteria.Since})
+	}
+
+	if criteria.Until != nil {
+		conditions = append(conditions, "a.created_at < ?")
+		args = append(args, sqlrepo.UTCTime{*criteria.Until})
+	}
+
+	return conditions, args
+}
+
+//list is a helper method to query for a list of Entries independent of the
+//actual query.
+func (r *Repo) list(ctx context.Context, query string, args ...interface{}) ([]*audit.Entry, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*audit.Entry{}
+	for rows.Next() {
+		e, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+
+	return result, rows.Err()
+}
+
+//scan is a helper function to scan a single Entry from a sql row or rows.
+func scan(s sqlrepo.Scanner) (*audit.Entry, error) {
+	e := &audit.Entry{}
+
+	var payload, before, after []byte
+	createdAt := sqlrepo.UTCTime{}
+
+	if err := s.Scan(
+		&e.Id,
+		&e.Actor,
+		&e.Command,
+		&payload,
+		&e.Entity,
+		&e.EntityId,
+		&before,
+		&after,
+		&createdAt,
+	); err != nil {
+		return nil, err
+	}
+
+	e.Payload, e.Before, e.After = payload, before, after
+	e.CreatedAt = createdAt.Time
+
+	return e, nil
+}
+
+//Add is the audit.Repo implementation.
+func (r *Repo) Add(ctx context.Context, e audit.Entry) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			`INSERT INTO %s (
+				id,
+				actor,
+				command,
+				payload,
+				entity,
+				entity_id,
+				snapshot_before,
+				snapshot_after,
+				created_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			Table,
+		),
+		e.Id,
+		e.Actor,
+		e.Command,
+		string(e.Payload),
+		e.Entity,
+		e.EntityId,
+		nullJSON(e.Before),
+		nullJSON(e.After),
+		sqlrepo.UTCTime{e.CreatedAt},
+	)
+
+	return r.db.TranslateError(err, audit.EntityName)
+}
+
+//nullJSON is a helper function to store raw as text, or NULL if raw is nil.
+func nullJSON(raw json.RawMessage) interface{} {
+	if raw == nil {
+		return nil
+	}
+	return string(raw)
+}
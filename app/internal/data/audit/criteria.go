@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//Criteria restricts which Entries are listed.
+//Each non-nil field must be satisfied by an Entry for it to be listed.
+//The zero value matches all Entries.
+type Criteria struct {
+	//EntityId, if not nil, must equal the Entry's EntityId.
+	EntityId *data.Id
+
+	//Since, if not nil, must not be after the Entry's CreatedAt field.
+	Since *time.Time
+
+	//Until, if not nil, must be after the Entry's CreatedAt field.
+	Until *time.Time
+}
+
+//Matches returns whether or not e satisfies c.
+func (c Criteria) Matches(e *Entry) bool {
+	if c.EntityId != nil && !e.EntityId.Equal(*c.EntityId) {
+		return false
+	}
+
+	if c.Since != nil && e.CreatedAt.Before(*c.Since) {
+		return false
+	}
+
+	if c.Until != nil && !e.CreatedAt.Before(*c.Until) {
+		return false
+	}
+
+	return true
+}
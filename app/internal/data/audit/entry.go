@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//EntityName is the name used to refer to Entry entities in errors and other
+//descriptions.
+const EntityName = "audit entry"
+
+//Entry is a domain type that records a single command executed in the application.
+//Entries are never updated or removed once they are added.
+type Entry struct {
+	//Id is the Entry's id.
+	Id data.Id
+
+	//Actor identifies who executed the command.
+	Actor string
+
+	//Command is the name of the executed command's type, e.g. "usercmd.CreateUserCommand".
+	Command string
+
+	//Payload is the JSON representation of the executed command.
+	Payload json.RawMessage
+
+	//Entity is the name of the type of entity the command operated on, e.g. "user".
+	Entity string
+
+	//EntityId is the Id of the entity the command operated on.
+	EntityId data.Id
+
+	//Before is the JSON representation of the entity before the command was executed.
+	//It is nil if the entity did not exist.
+	Before json.RawMessage
+
+	//After is the JSON representation of the entity after the command was executed.
+	//It is nil if the entity no longer exists.
+	After json.RawMessage
+
+	//CreatedAt is the time at which the command was executed.
+	//It has microsecond precision so that it survives a round trip through the database.
+	CreatedAt time.Time
+}
@@ -0,0 +1,19 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//Key returns the sort key of e.
+//Entries are sorted by their CreatedAt time and then by their Id.
+func Key(e *Entry) string {
+	return data.TimeKey(e.CreatedAt, e.Id)
+}
+
+//ParseKey returns the CreatedAt time and Id encoded in key by Key.
+//data.ErrInvalidCursor is returned if key was not created by Key.
+func ParseKey(key string) (time.Time, data.Id, error) {
+	return data.ParseTimeKey(key)
+}
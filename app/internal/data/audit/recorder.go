@@ -0,0 +1,118 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/gogolfing/cbus"
+)
+
+//Target describes the entities that a group of commands operate on so that a
+//Recorder can identify and snapshot them.
+type Target struct {
+	//Entity is the name of the type of entity, e.g. "user".
+	Entity string
+
+	//Id should return the Id of the entity that cmd operates on and true.
+	//
+	//It is called with a nil result before cmd is executed, and should return
+	//false if the Id is not known until then, e.g. for create commands.
+	//It is called again with the handler's result after cmd is executed.
+	Id func(cmd cbus.Command, result interface{}) (data.Id, bool)
+
+	//Snapshot should return the current state of the entity with id.
+	//A *data.NotFoundError should be returned if the entity does not exist.
+	Snapshot func(ctx context.Context, id data.Id) (interface{}, error)
+}
+
+//Recorder records an Entry for every successful execution of the handlers it wraps.
+type Recorder struct {
+	//Entries is where recorded Entries are added.
+	Entries Repo
+}
+
+//Handler returns a cbus.Handler that executes next and records an Entry for
+//the command if next succeeds.
+//Target is used to identify and snapshot the entity the command operates on.
+//
+//If the Entry cannot be recorded, the handler's result is returned along with
+//the error from recording.
+func (r *Recorder) Handler(target Target, next cbus.Handler) cbus.Handler {
+	return cbus.HandlerFunc(func(ctx context.Context, cmd cbus.Command) (interface{}, error) {
+		entry := Entry{
+			Actor:   ActorFromContext(ctx),
+			Command: commandName(cmd),
+			Entity:  target.Entity,
+		}
+
+		var err error
+
+		if id, ok := target.Id(cmd, nil); ok {
+			if entry.Before, err = r.snapshot(ctx, target, id); err != nil {
+				return nil, err
+			}
+		}
+
+		result, err := next.Handle(ctx, cmd)
+		if err != nil {
+			return result, err
+		}
+
+		if err := r.record(ctx, target, cmd, result, entry); err != nil {
+			return result, err
+		}
+
+		return result, nil
+	})
+}
+
+//record fills in the rest of entry from the executed cmd and its result and
+//adds it to r.Entries.
+func (r *Recorder) record(ctx context.Context, target Target, cmd cbus.Command, result interface{}, entry Entry) error {
+	var err error
+
+	entry.Id, err = data.NewId()
+	if err != nil {
+		return err
+	}
+
+	entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	if entry.Payload, err = json.Marshal(cmd); err != nil {
+		return err
+	}
+
+	entry.EntityId = data.EmptyId()
+	if id, ok := target.Id(cmd, result); ok {
+		entry.EntityId = id
+		if entry.After, err = r.snapshot(ctx, target, id); err != nil {
+			return err
+		}
+	}
+
+	return r.Entries.Add(ctx, entry)
+}
+
+//snapshot returns the JSON representation of the entity with id.
+//The result is nil if the entity does not exist.
+func (r *Recorder) snapshot(ctx context.Context, target Target, id data.Id) (json.RawMessage, error) {
+	entity, err := target.Snapshot(ctx, id)
+	if data.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(entity)
+}
+
+//commandName returns the name of cmd's type without any pointer indirection,
+//e.g. "usercmd.CreateUserCommand".
+func commandName(cmd cbus.Command) string {
+	return strings.TrimLeft(fmt.Sprintf("%T", cmd), "*")
+}
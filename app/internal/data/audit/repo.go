@@ -0,0 +1,30 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//QueryRepo provides methods for retrieving Entries.
+type QueryRepo interface {
+	//ListPage should return the page of Entries that match criteria described by
+	//page.
+	//They should be sorted by their CreatedAt time and then by their Id.
+	//The Page's Total is the number of Entries that match criteria.
+	//The Page's Next cursor is keyed on the Key of the last Entry.
+	//
+	//data.ErrInvalidCursor should be returned if page.Cursor is invalid.
+	ListPage(ctx context.Context, criteria Criteria, page data.PageRequest) ([]*Entry, data.Page, error)
+}
+
+//Repo provides methods for adding Entries as well as promotes the QueryRepo
+//interface.
+//There are no methods to update or remove Entries.
+type Repo interface {
+	//QueryRepo is promoted here to indicate a Repo contains all query methods.
+	QueryRepo
+
+	//Add should add e to the underlying storage repository.
+	Add(ctx context.Context, e Entry) error
+}
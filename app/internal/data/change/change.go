@@ -0,0 +1,74 @@
+package change
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//EntityName is the name used to describe Change entities.
+const EntityName = "change"
+
+//Operations describing what happened to an entity.
+const (
+	//OpUpsert indicates an entity was created or updated.
+	OpUpsert = "upsert"
+
+	//OpDelete indicates an entity was deleted.
+	//The Change is a tombstone without Data.
+	OpDelete = "delete"
+)
+
+//Change is a single mutation of an entity in the change feed.
+//Changes are ordered by Seq, which increases by exactly one with each Change.
+//
+//Deleting a Client records an OpUpsert Change for each of its Users, without the
+//Client, before the Client's OpDelete Change.
+type Change struct {
+	//Seq is the Change's position in the feed.
+	Seq int64
+
+	//Entity is the name of the type of entity that changed, e.g. user.EntityName.
+	Entity string
+
+	//EntityId is the Id of the entity that changed.
+	EntityId data.Id
+
+	//Op is one of the Op* constants.
+	Op string
+
+	//Data is the entity after an OpUpsert Change, and nil for an OpDelete Change.
+	Data json.RawMessage
+
+	//CreatedAt is when the Change was recorded.
+	CreatedAt time.Time
+}
+
+//Upsert returns a new OpUpsert Change for the entity v named entity with id.
+//Seq is assigned when the Change is stored.
+func Upsert(entity string, id data.Id, v interface{}) (Change, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return Change{}, err
+	}
+
+	c := newChange(entity, id, OpUpsert)
+	c.Data = body
+	return c, nil
+}
+
+//Delete returns a new OpDelete Change for the entity named entity with id.
+//Seq is assigned when the Change is stored.
+func Delete(entity string, id data.Id) Change {
+	return newChange(entity, id, OpDelete)
+}
+
+func newChange(entity string, id data.Id, op string) Change {
+	return Change{
+		Entity:    entity,
+		EntityId:  id,
+		Op:        op,
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
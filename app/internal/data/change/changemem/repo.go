@@ -0,0 +1,83 @@
+package changemem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/memrepo"
+)
+
+var _ change.QueryRepo = &Repo{} //Ensure *Repo is a change.QueryRepo.
+
+const (
+	//Table is the memrepo table we store our changes in.
+	Table = "changes"
+
+	//TableSequence is the memrepo table with the counter row that assigns Seqs.
+	TableSequence = "change_sequence"
+)
+
+//Repo is a change.QueryRepo implementation that stores Changes in memory.
+type Repo struct {
+	mem *memrepo.Repo
+}
+
+//New returns a new Repo that uses repo as its storage.
+func New(repo *memrepo.Repo) *Repo {
+	return &Repo{
+		mem: repo,
+	}
+}
+
+//ListSince is the change.QueryRepo implementation.
+func (r *Repo) ListSince(ctx context.Context, since int64, limit int) ([]*change.Change, error) {
+	result := []*change.Change{}
+
+	err := r.mem.ReadWorkContext(ctx, func(tx *memrepo.Tx) error {
+		for _, row := range tx.Rows(Table) {
+			c := row.(change.Change)
+			if c.Seq > since {
+				result = append(result, &c)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Seq < result[j].Seq
+	})
+
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+//Append stores changes with the next Seqs in tx, which must be the transaction
+//of the mutations the changes describe.
+func Append(tx *memrepo.Tx, changes ...change.Change) error {
+	//The counter row is stored under the empty id since the table has no other rows.
+	var last int64
+	if row, ok := tx.Get(TableSequence, data.EmptyId()); ok {
+		last = row.(int64)
+	}
+
+	for _, c := range changes {
+		id, err := data.NewId()
+		if err != nil {
+			return err
+		}
+
+		last++
+		c.Seq = last
+		tx.Put(Table, id, c)
+	}
+
+	tx.Put(TableSequence, data.EmptyId(), last)
+	return nil
+}
@@ -0,0 +1,146 @@
+package changesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/sqlrepo"
+)
+
+var _ change.QueryRepo = &Repo{} //Ensure *Repo is a change.QueryRepo.
+
+const (
+	//Table is the table we store our changes in.
+	Table = "changes"
+
+	//TableSequence is the table with the counter row that assigns Seqs.
+	TableSequence = "change_sequence"
+
+	//SequenceName is the name of the counter row in TableSequence.
+	SequenceName = "changes"
+)
+
+//SelectFrom is our select query without filtering, ordering, etc.
+const SelectFrom = `SELECT
+	c.seq,
+	c.entity,
+	c.entity_id,
+	c.op,
+	c.data,
+	c.created_at
+	FROM ` + Table + ` AS c`
+
+//Repo is a change.QueryRepo implementation that uses a SQL database as its storage.
+type Repo struct {
+	db *sqlrepo.Repo
+}
+
+//New returns a new Repo that uses repo to talk to the database.
+func New(repo *sqlrepo.Repo) *Repo {
+	return &Repo{
+		db: repo,
+	}
+}
+
+//ListSince is the change.QueryRepo implementation.
+func (r *Repo) ListSince(ctx context.Context, since int64, limit int) ([]*change.Change, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		SelectFrom+" WHERE c.seq > ? ORDER BY c.seq ASC LIMIT ?",
+		since,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*change.Change{}
+	for rows.Next() {
+		c, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+
+	return result, rows.Err()
+}
+
+func scan(s sqlrepo.Scanner) (*change.Change, error) {
+	c := &change.Change{}
+	var body sql.NullString
+	createdAt := sqlrepo.UTCTime{}
+
+	err := s.Scan(
+		&c.Seq,
+		&c.Entity,
+		&c.EntityId,
+		&c.Op,
+		&body,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if body.Valid {
+		c.Data = []byte(body.String)
+	}
+	c.CreatedAt = createdAt.Time
+
+	return c, nil
+}
+
+//Append stores changes with the next Seqs using qec, which must be the
+//transaction of the mutations the changes describe.
+//
+//Seqs are assigned by incrementing the counter row in TableSequence.
+//The row stays locked until the transaction ends, so concurrent transactions
+//commit their Changes in Seq order and a rolled back transaction leaves no gap.
+func Append(ctx context.Context, qec sqlrepo.QueryExecerContext, changes ...change.Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	_, err := qec.ExecContext(
+		ctx,
+		fmt.Sprintf("UPDATE %s SET value = value + ? WHERE name = ?", TableSequence),
+		len(changes),
+		SequenceName,
+	)
+	if err != nil {
+		return err
+	}
+
+	var last int64
+	err = qec.QueryRowContext(
+		ctx,
+		fmt.Sprintf("SELECT value FROM %s WHERE name = ?", TableSequence),
+		SequenceName,
+	).Scan(&last)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (seq, entity, entity_id, op, data, created_at) VALUES %s",
+		Table,
+		sqlrepo.List("(?,?,?,?,?,?)", len(changes)),
+	)
+
+	first := last - int64(len(changes)) + 1
+	args := make([]interface{}, 0, 6*len(changes))
+	for i, c := range changes {
+		var body interface{}
+		if c.Data != nil {
+			body = string(c.Data)
+		}
+		args = append(args, first+int64(i), c.Entity, c.EntityId, c.Op, body, sqlrepo.UTCTime{c.CreatedAt})
+	}
+
+	_, err = qec.ExecContext(ctx, query, args...)
+	return err
+}
@@ -0,0 +1,14 @@
+package change
+
+import "context"
+
+//QueryRepo provides methods for retrieving Changes.
+//
+//Changes are not added through a repository.
+//They are recorded by the entity repositories in the same transaction as the
+//mutations they describe, so that the feed never has gaps.
+type QueryRepo interface {
+	//ListSince should return up to limit Changes with a Seq greater than since,
+	//ordered by Seq.
+	ListSince(ctx context.Context, since int64, limit int) ([]*Change, error)
+}
@@ -6,6 +6,10 @@ import (
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
 )
 
+//EntityName is the name used to refer to Client entities in errors and other
+//descriptions.
+const EntityName = "client"
+
 //Client is a domain type that represents a client inside the application.
 //
 //This could be thought of as a business project or business unit.
@@ -28,4 +32,8 @@ type Client struct {
 
 	//UpdatedAt is the time at which this Client was most recently updated.
 	UpdatedAt time.Time
+
+	//Version is incremented every time this Client is updated.
+	//New Clients start at version 1.
+	Version int
 }
@@ -6,6 +6,8 @@ import (
 
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
 	"github.com/gogolfing/cbus"
 )
 
@@ -13,22 +15,33 @@ import (
 type Handler struct {
 	//Clients is the Client repository to use within Command handling.
 	Clients client.Repo
+
+	//Users is the User repository that Memberships of deleted Clients are
+	//removed from.
+	Users user.Repo
+
+	//Events is where events are published after Clients are successfully changed.
+	Events event.Publisher
 }
 
 //CreateClient attempts to create a new Client and add it to h.Clients.
 //Cmd must be of type *CreateClientCommand.
 //The result, if not nil and without error, will be a *client.Client.
+//
+//A client.ClientCreated event is published.
 func (h *Handler) CreateClient(ctx context.Context, cmd cbus.Command) (interface{}, error) {
 	createClient := cmd.(*CreateClientCommand)
 
-	client, err := createClient.newClient()
+	c, err := createClient.newClient()
 	if err != nil {
 		return nil, err
 	}
 
-	err = h.Clients.Add(ctx, *client)
+	if err := h.Clients.Add(ctx, *c); err != nil {
+		return c, err
+	}
 
-	return client, err
+	return c, h.Events.Publish(ctx, client.ClientCreated{Client: *c})
 }
 
 //CreateClientCommand is Command that should be used to create a new Client and
@@ -51,25 +64,58 @@ func (c *CreateClientCommand) newClient() (*client.Client, error) {
 		Name:      c.Name,
 		CreatedAt: now,
 		UpdatedAt: now,
+		Version:   1,
 	}, nil
 }
 
 //UpdateClient attempts to retrieve and set a Client using h.Clients.
 //Cmd must be of type *UpdateClientCommand.
 //The result, if not nil and without error, will be a *client.Client.
+//A *data.NotFoundError is returned if the Client does not exist.
+//A *data.VersionMismatchError is returned if the Client is not at the command's
+//Version or if it is changed concurrently.
+//
+//A client.ClientRenamed event is published if the Client's Name changed.
 func (h *Handler) UpdateClient(ctx context.Context, cmd cbus.Command) (interface{}, error) {
 	updateClient := cmd.(*UpdateClientCommand)
 
-	client, err := h.Clients.Get(ctx, updateClient.Id)
+	c, err := h.getVersion(ctx, updateClient.Id, updateClient.Version)
 	if err != nil {
 		return nil, err
 	}
+	name := c.Name
 
-	updateClient.updateClient(client)
+	updateClient.updateClient(c)
+
+	if err := h.Clients.Set(ctx, *c); err != nil {
+		return nil, err
+	}
+	c.Version++
 
-	err = h.Clients.Set(ctx, *client)
+	if c.Name == name {
+		return c, nil
+	}
 
-	return client, err
+	return c, h.Events.Publish(ctx, client.ClientRenamed{
+		ClientId: c.Id,
+		OldName:  name,
+		NewName:  c.Name,
+	})
+}
+
+//getVersion is a helper method to get the Client with id from h.Clients and
+//make sure it is at version if version is not nil.
+func (h *Handler) getVersion(ctx context.Context, id data.Id, version *int) (*client.Client, error) {
+	c, err := h.Clients.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != nil && c.Version != *version {
+		return nil, data.NewVersionMismatchError(client.EntityName, id)
+	}
+
+	return c, nil
 }
 
 //UpdateClientCommand is a Command to update a Client.
@@ -79,6 +125,9 @@ type UpdateClientCommand struct {
 
 	//Name, if not nil, is the name to set on the Client.
 	Name *string
+
+	//Version, if not nil, is the Version the Client must be at to be updated.
+	Version *int
 }
 
 func (c *UpdateClientCommand) updateClient(client *client.Client) {
@@ -95,14 +144,91 @@ func (c *UpdateClientCommand) updateClient(client *client.Client) {
 //Cmd must be of type *DeleteClientCommand.
 //The result will always be nil.
 //A non-nil error will be returned to indicate failure.
+//A *data.NotFoundError is returned if the Client does not exist.
+//A *data.VersionMismatchError is returned if the Client is not at the command's Version.
+//
+//The Client is removed from every User that has it first, so that those Users are
+//updated as if the Client were removed from each of them.
+//This should happen inside of a transaction so that a failure to remove the Client
+//leaves the Users unchanged.
+//
+//A user.UserUpdated and user.UserClientsChanged event is published for each of
+//those Users, followed by a client.ClientDeleted event.
 func (h *Handler) DeleteClient(ctx context.Context, cmd cbus.Command) (interface{}, error) {
 	deleteClient := cmd.(*DeleteClientCommand)
 
-	return nil, h.Clients.Remove(ctx, deleteClient.Id)
+	c, err := h.Clients.Get(ctx, deleteClient.Id)
+	if err != nil {
+		return nil, err
+	}
+	if deleteClient.Version != nil && c.Version != *deleteClient.Version {
+		return nil, data.NewVersionMismatchError(client.EntityName, c.Id)
+	}
+
+	events, err := h.removeUsers(ctx, c.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.Clients.Remove(ctx, deleteClient.Id, deleteClient.Version); err != nil {
+		return nil, err
+	}
+
+	events = append(events, client.ClientDeleted{ClientId: deleteClient.Id})
+
+	return nil, h.Events.Publish(ctx, events...)
+}
+
+//removeUsers removes the Client with clientId from all of the Users in h.Users
+//and returns the events describing the changes.
+func (h *Handler) removeUsers(ctx context.Context, clientId data.Id) ([]event.Event, error) {
+	//All of the Users are listed before any are changed since changing them
+	//changes the pages.
+	users := []*user.User{}
+	page := data.PageRequest{Limit: data.MaxPageLimit}
+	for {
+		result, p, err := h.Users.ListByClient(ctx, clientId, page)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, result...)
+
+		if p.Next == "" {
+			break
+		}
+		page.Cursor = p.Next
+	}
+
+	now := time.Now()
+	events := []event.Event{}
+	for _, u := range users {
+		u, removed, err := h.Users.RemoveClient(ctx, u.Id, clientId, nil, now)
+		if err != nil {
+			return nil, err
+		}
+		if !removed {
+			continue
+		}
+
+		events = append(
+			events,
+			user.UserUpdated{User: *u},
+			user.UserClientsChanged{
+				UserId:    u.Id,
+				ClientIds: u.ClientIds(),
+				Removed:   []data.Id{clientId},
+			},
+		)
+	}
+
+	return events, nil
 }
 
 //DeleteClientCommand is a Command to delete (remove) a Client.
 type DeleteClientCommand struct {
 	//Id is the Id of the Client to delete.
 	Id data.Id
+
+	//Version, if not nil, is the Version the Client must be at to be deleted.
+	Version *int
 }
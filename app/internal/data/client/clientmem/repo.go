@@ -0,0 +1,229 @@
+package clientmem
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change/changemem"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/memrepo"
+)
+
+var _ client.Repo = &Repo{} //Ensure *Repo is a client.Repo.
+
+//Table is the memrepo table we store our client entities in.
+const Table = "clients"
+
+//RelationUserClients is the memrepo relation for user, client relationships.
+//It must match the relation defined by the user memory repository.
+const RelationUserClients = "user_clients"
+
+//Repo is a client.Repo implementation that stores Clients in memory.
+type Repo struct {
+	mem *memrepo.Repo
+}
+
+//New returns a new Repo that uses repo as its storage.
+func New(repo *memrepo.Repo) *Repo {
+	return &Repo{
+		mem: repo,
+	}
+}
+
+//Get is the client.QueryRepo implementation.
+func (r *Repo) Get(ctx context.Context, id data.Id) (*client.Client, error) {
+	var result *client.Client
+
+	err := r.mem.ReadWorkContext(ctx, func(tx *memrepo.Tx) error {
+		row, ok := tx.Get(Table, id)
+		if !ok {
+			return data.NewNotFoundError(client.EntityName, "id", id)
+		}
+
+		result = fromRow(row)
+		return nil
+	})
+
+	return result, err
+}
+
+//List is the client.QueryRepo implementation.
+func (r *Repo) List(ctx context.Context) ([]*client.Client, error) {
+	return r.list(ctx, func(*client.Client) bool {
+		return true
+	})
+}
+
+//ListPage is the client.QueryRepo implementation.
+func (r *Repo) ListPage(ctx context.Context, criteria client.Criteria, page data.PageRequest) ([]*client.Client, data.Page, error) {
+	after, err := data.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	var withUsers map[data.Id]bool
+	if criteria.HasUsers != nil {
+		err := r.mem.ReadWorkContext(ctx, func(tx *memrepo.Tx) error {
+			withUsers = tx.LinkedTo(RelationUserClients)
+			return nil
+		})
+		if err != nil {
+			return nil, data.Page{}, err
+		}
+	}
+
+	clients, err := r.list(ctx, func(c *client.Client) bool {
+		if !strings.HasPrefix(c.Name, criteria.NamePrefix) {
+			return false
+		}
+		if criteria.HasUsers != nil && withUsers[c.Id] != *criteria.HasUsers {
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	total := len(clients)
+	start := sort.Search(total, func(i int) bool {
+		return clients[i].Name > after
+	})
+	clients = clients[start:]
+	if len(clients) > page.Limit+1 {
+		clients = clients[:page.Limit+1]
+	}
+
+	n, result := page.Paginate(len(clients), total, func(i int) string {
+		return clients[i].Name
+	})
+
+	return clients[:n], result, nil
+}
+
+//ListByIds is the client.QueryRepo implementation.
+func (r *Repo) ListByIds(ctx context.Context, ids []data.Id) ([]*client.Client, error) {
+	if len(ids) == 0 {
+		return []*client.Client{}, nil
+	}
+
+	set := make(map[data.Id]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+
+	return r.list(ctx, func(c *client.Client) bool {
+		return set[c.Id]
+	})
+}
+
+//list is a helper method to return all Clients for which include returns true
+//in the order the application expects.
+func (r *Repo) list(ctx context.Context, include func(*client.Client) bool) ([]*client.Client, error) {
+	result := []*client.Client{}
+
+	err := r.mem.ReadWorkContext(ctx, func(tx *memrepo.Tx) error {
+		for _, row := range tx.Rows(Table) {
+			c := fromRow(row)
+			if include(c) {
+				result = append(result, c)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortClients(result)
+
+	return result, nil
+}
+
+//sortClients sorts clients by name ascending.
+func sortClients(clients []*client.Client) {
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].Name < clients[j].Name
+	})
+}
+
+//fromRow is a helper function to return a new Client copied from a stored row.
+func fromRow(row interface{}) *client.Client {
+	c := row.(client.Client)
+	return &c
+}
+
+//Add is the client.Repo implementation.
+func (r *Repo) Add(ctx context.Context, c client.Client) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		if _, ok := tx.Get(Table, c.Id); ok {
+			return data.NewConflictError(client.EntityName, "id")
+		}
+		if err := checkUniqueName(tx, c); err != nil {
+			return err
+		}
+
+		return put(tx, c)
+	})
+}
+
+//Set is the client.Repo implementation.
+func (r *Repo) Set(ctx context.Context, c client.Client) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		row, ok := tx.Get(Table, c.Id)
+		if !ok {
+			return data.NewNotFoundError(client.EntityName, "id", c.Id)
+		}
+		if row.(client.Client).Version != c.Version {
+			return data.NewVersionMismatchError(client.EntityName, c.Id)
+		}
+		if err := checkUniqueName(tx, c); err != nil {
+			return err
+		}
+
+		c.Version++
+		return put(tx, c)
+	})
+}
+
+//Remove is the client.Repo implementation.
+func (r *Repo) Remove(ctx context.Context, id data.Id, version *int) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		row, ok := tx.Get(Table, id)
+		if !ok {
+			return data.NewNotFoundError(client.EntityName, "id", id)
+		}
+		if version != nil && row.(client.Client).Version != *version {
+			return data.NewVersionMismatchError(client.EntityName, id)
+		}
+
+		tx.Delete(Table, id)
+		return changemem.Append(tx, change.Delete(client.EntityName, id))
+	})
+}
+
+//put is a helper function to store c and record it in the change feed.
+func put(tx *memrepo.Tx, c client.Client) error {
+	entry, err := change.Upsert(client.EntityName, c.Id, c)
+	if err != nil {
+		return err
+	}
+
+	tx.Put(Table, c.Id, c)
+	return changemem.Append(tx, entry)
+}
+
+//checkUniqueName returns a *data.ConflictError if another Client in tx already
+//has c's Name.
+func checkUniqueName(tx *memrepo.Tx, c client.Client) error {
+	for _, row := range tx.Rows(Table) {
+		other := row.(client.Client)
+		if other.Name == c.Name && !other.Id.Equal(c.Id) {
+			return data.NewConflictError(client.EntityName, "name")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package clientmem_test
+
+import (
+	"testing"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client/clientmem"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client/clienttest"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/memrepo"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user/usermem"
+)
+
+func TestRepo(t *testing.T) {
+	clienttest.TestRepo(t, func(t *testing.T) (client.Repo, user.Repo) {
+		mem := memrepo.New()
+		users := usermem.New(mem)
+		return clientmem.New(mem), users
+	})
+}
@@ -3,8 +3,11 @@ package clientsql
 import (
 	"context"
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change/changesql"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/sqlrepo"
 )
@@ -14,14 +17,21 @@ var _ client.Repo = &Repo{} //Ensure *Repo is a client.Repo.
 //Table is table we are querying for our client entities.
 const Table = "clients"
 
+//TableUserClients is the table for user, client relationships.
+const TableUserClients = "user_clients"
+
 //SelectFrom is the query to get all of our columns without any filtering, ordering, etc.
 const SelectFrom = `SELECT
 	c.id,
 	c.name,
 	c.created_at,
-	c.updated_at
+	c.updated_at,
+	c.version
 	FROM ` + Table + ` AS c`
 
+//CountFrom is the query to count all of our rows without any filtering.
+const CountFrom = `SELECT COUNT(*) FROM ` + Table + ` AS c`
+
 //Repo is a client.Repo implementation that uses a SQL database as storage.
 type Repo struct {
 	db *sqlrepo.Repo
@@ -41,18 +51,89 @@ func (r *Repo) Get(ctx context.Context, id data.Id) (*client.Client, error) {
 		fmt.Sprintf("%s WHERE id = ?", SelectFrom),
 		id,
 	)
-	return scan(row)
+	c, err := scan(row)
+	if err != nil {
+		return nil, sqlrepo.NoRows(err, data.NewNotFoundError(client.EntityName, "id", id))
+	}
+
+	return c, nil
 }
 
 //List is the client.QueryRepo implementation.
 func (r *Repo) List(ctx context.Context) ([]*client.Client, error) {
 	query := orderQuery(SelectFrom)
-	clients, err := r.list(ctx, query)
+	clients, err := r.list(ctx, r.db, query)
 
 	return clients, err
 }
 
-//List is the client.QueryRepo implementation.
+//ListPage is the client.QueryRepo implementation.
+//The Clients are counted and listed in a single read transaction so that the
+//Page describes them.
+func (r *Repo) ListPage(ctx context.Context, criteria client.Criteria, page data.PageRequest) ([]*client.Client, data.Page, error) {
+	after, err := data.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	conditions, args := criteriaConditions(criteria)
+	count := CountFrom + sqlrepo.Where(conditions...)
+	countArgs := args
+
+	if after != "" {
+		conditions = append(conditions, "c.name > ?")
+		args = append(args, after)
+	}
+	query := orderQuery(SelectFrom+sqlrepo.Where(conditions...)) + " LIMIT ?"
+	args = append(args, page.Limit+1)
+
+	var clients []*client.Client
+	total := 0
+
+	err = r.db.ReadWorkContext(ctx, func(q sqlrepo.QueryerContext) error {
+		if err := q.QueryRowContext(ctx, count, countArgs...).Scan(&total); err != nil {
+			return err
+		}
+
+		var err error
+		clients, err = r.list(ctx, q, query, args...)
+		return err
+	})
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	n, result := page.Paginate(len(clients), total, func(i int) string {
+		return clients[i].Name
+	})
+
+	return clients[:n], result, nil
+}
+
+//criteriaConditions is a helper function to translate criteria into query
+//conditions and their arguments.
+func criteriaConditions(criteria client.Criteria) ([]string, []interface{}) {
+	conditions, args := []string{}, []interface{}{}
+
+	if criteria.NamePrefix != "" {
+		//substr is used instead of LIKE so that the prefix does not need escaping
+		//and matching is case sensitive in all DBMSs.
+		conditions = append(conditions, "substr(c.name, 1, ?) = ?")
+		args = append(args, utf8.RuneCountInString(criteria.NamePrefix), criteria.NamePrefix)
+	}
+
+	if criteria.HasUsers != nil {
+		exists := fmt.Sprintf("EXISTS (SELECT 1 FROM %s AS uc WHERE uc.client_id = c.id)", TableUserClients)
+		if !*criteria.HasUsers {
+			exists = "NOT " + exists
+		}
+		conditions = append(conditions, exists)
+	}
+
+	return conditions, args
+}
+
+//ListByIds is the client.QueryRepo implementation.
 func (r *Repo) ListByIds(ctx context.Context, ids []data.Id) ([]*client.Client, error) {
 	if len(ids) == 0 {
 		return []*client.Client{}, nil
@@ -63,7 +144,7 @@ func (r *Repo) ListByIds(ctx context.Context, ids []data.Id) ([]*client.Client,
 	query := fmt.Sprintf("%s WHERE id IN (%s)", SelectFrom, placeholders)
 	query = orderQuery(query)
 
-	return r.list(ctx, query, args...)
+	return r.list(ctx, r.db, query, args...)
 }
 
 //orderQuery is a helper function to take an unordered select query and add
@@ -72,10 +153,10 @@ func orderQuery(query string) string {
 	return fmt.Sprintf("%s ORDER BY name ASC", query)
 }
 
-//list is a helper method to query for a list of Clients independent of the
-//actual query.
-func (r *Repo) list(ctx context.Context, query string, args ...interface{}) ([]*client.Client, error) {
-	rows, err := r.db.QueryContext(ctx, query, args...)
+//list is a helper method to query for a list of Clients with q independent of
+//the actual query.
+func (r *Repo) list(ctx context.Context, q sqlrepo.QueryerContext, query string, args ...interface{}) ([]*client.Client, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -105,6 +186,7 @@ func scan(s sqlrepo.Scanner) (*client.Client, error) {
 		&client.Name,
 		&createdAt,
 		&updatedAt,
+		&client.Version,
 	)
 	if err != nil {
 		return nil, err
@@ -120,42 +202,78 @@ func (r *Repo) Add(ctx context.Context, c client.Client) error {
 	work := func(qec sqlrepo.QueryExecerContext) error {
 		_, err := qec.ExecContext(
 			ctx,
-			fmt.Sprintf("INSERT INTO %s (id, name, created_at, updated_at) VALUES (?, ?, ?, ?)", Table),
+			fmt.Sprintf("INSERT INTO %s (id, name, created_at, updated_at, version) VALUES (?, ?, ?, ?, ?)", Table),
 			c.Id,
 			c.Name,
 			sqlrepo.UTCTime{c.CreatedAt},
 			sqlrepo.UTCTime{c.UpdatedAt},
+			c.Version,
 		)
-		return err
+		if err != nil {
+			return err
+		}
+		return appendUpsert(ctx, qec, c)
 	}
-	return r.db.TxWorkContext(ctx, work)
+	return r.db.TranslateError(r.db.TxWorkContext(ctx, work), client.EntityName)
 }
 
 //Set is the client.Repo implementation.
 func (r *Repo) Set(ctx context.Context, c client.Client) error {
 	work := func(qec sqlrepo.QueryExecerContext) error {
-		_, err := qec.ExecContext(
+		result, err := qec.ExecContext(
 			ctx,
-			fmt.Sprintf("UPDATE %s SET name = ?, created_at = ?, updated_at = ? WHERE id = ?", Table),
+			fmt.Sprintf("UPDATE %s SET name = ?, created_at = ?, updated_at = ?, version = version + 1 WHERE id = ? AND version = ?", Table),
 			c.Name,
 			sqlrepo.UTCTime{c.CreatedAt},
 			sqlrepo.UTCTime{c.UpdatedAt},
 			c.Id,
+			c.Version,
 		)
-		return err
+		err = sqlrepo.RequireVersionedUpdate(
+			ctx,
+			qec,
+			result,
+			err,
+			Table,
+			c.Id,
+			data.NewNotFoundError(client.EntityName, "id", c.Id),
+			data.NewVersionMismatchError(client.EntityName, c.Id),
+		)
+		if err != nil {
+			return err
+		}
+
+		c.Version++
+		return appendUpsert(ctx, qec, c)
 	}
-	return r.db.TxWorkContext(ctx, work)
+	return r.db.TranslateError(r.db.TxWorkContext(ctx, work), client.EntityName)
 }
 
 //Remove is the client.Repo implementation.
-func (r *Repo) Remove(ctx context.Context, id data.Id) error {
+func (r *Repo) Remove(ctx context.Context, id data.Id, version *int) error {
 	work := func(qec sqlrepo.QueryExecerContext) error {
-		_, err := qec.ExecContext(
+		err := sqlrepo.DeleteVersioned(
 			ctx,
-			fmt.Sprintf("DELETE FROM %s WHERE id = ?", Table),
+			qec,
+			Table,
 			id,
+			version,
+			data.NewNotFoundError(client.EntityName, "id", id),
+			data.NewVersionMismatchError(client.EntityName, id),
 		)
-		return err
+		if err != nil {
+			return err
+		}
+		return changesql.Append(ctx, qec, change.Delete(client.EntityName, id))
 	}
 	return r.db.TxWorkContext(ctx, work)
 }
+
+//appendUpsert is a helper function to record c as stored in the change feed.
+func appendUpsert(ctx context.Context, qec sqlrepo.QueryExecerContext, c client.Client) error {
+	entry, err := change.Upsert(client.EntityName, c.Id, c)
+	if err != nil {
+		return err
+	}
+	return changesql.Append(ctx, qec, entry)
+}
@@ -0,0 +1,19 @@
+package clientsql_test
+
+import (
+	"testing"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client/clientsql"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client/clienttest"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/sqlrepo/sqlrepotest"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user/usersql"
+)
+
+func TestRepo(t *testing.T) {
+	clienttest.TestRepo(t, func(t *testing.T) (client.Repo, user.Repo) {
+		repo := sqlrepotest.Open(t)
+		return clientsql.New(repo), usersql.New(repo)
+	})
+}
@@ -0,0 +1,258 @@
+package clienttest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user/usertest"
+)
+
+//NewRepos is a function type that returns new, empty repositories that share
+//their storage so that the Users in users can have Memberships of Clients.
+type NewRepos func(t *testing.T) (clients client.Repo, users user.Repo)
+
+//TestRepo tests that the repositories returned by newRepos have the semantics
+//described by client.Repo, so that every implementation behaves the same.
+func TestRepo(t *testing.T, newRepos NewRepos) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, clients client.Repo, users user.Repo)
+	}{
+		{"Add", testAdd},
+		{"AddConflict", testAddConflict},
+		{"Set", testSet},
+		{"Remove", testRemove},
+		{"RemoveMemberships", testRemoveMemberships},
+		{"List", testList},
+		{"ListPage", testListPage},
+		{"ListPageCriteria", testListPageCriteria},
+		{"ListByIds", testListByIds},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clients, users := newRepos(t)
+			test.test(t, clients, users)
+		})
+	}
+}
+
+func testAdd(t *testing.T, clients client.Repo, users user.Repo) {
+	c := usertest.AddClient(t, clients, "acme")
+
+	got, err := clients.Get(context.Background(), c.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireClient(t, got, c)
+
+	if _, err := clients.Get(context.Background(), usertest.NewId(t)); !data.IsNotFound(err) {
+		t.Errorf("Get(unknown) error = %v, want a not found error", err)
+	}
+}
+
+func testAddConflict(t *testing.T, clients client.Repo, users user.Repo) {
+	c := usertest.AddClient(t, clients, "acme")
+
+	other := *c
+	other.Id = usertest.NewId(t)
+	if err := clients.Add(context.Background(), other); !data.IsConflict(err) {
+		t.Errorf("Add(same name) error = %v, want a conflict error", err)
+	}
+
+	other = *c
+	other.Name = "other"
+	if err := clients.Add(context.Background(), other); !data.IsConflict(err) {
+		t.Errorf("Add(same id) error = %v, want a conflict error", err)
+	}
+}
+
+func testSet(t *testing.T, clients client.Repo, users user.Repo) {
+	usertest.AddClient(t, clients, "taken")
+	c := usertest.AddClient(t, clients, "acme")
+
+	c.Name = "renamed"
+	c.UpdatedAt = c.UpdatedAt.Add(time.Minute)
+	if err := clients.Set(context.Background(), *c); err != nil {
+		t.Fatal(err)
+	}
+	c.Version++
+
+	got, err := clients.Get(context.Background(), c.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireClient(t, got, c)
+
+	stale := *c
+	stale.Version--
+	if err := clients.Set(context.Background(), stale); !data.IsVersionMismatch(err) {
+		t.Errorf("Set(stale version) error = %v, want a version mismatch error", err)
+	}
+
+	taken := *c
+	taken.Name = "taken"
+	if err := clients.Set(context.Background(), taken); !data.IsConflict(err) {
+		t.Errorf("Set(taken name) error = %v, want a conflict error", err)
+	}
+
+	unknown := *c
+	unknown.Id = usertest.NewId(t)
+	if err := clients.Set(context.Background(), unknown); !data.IsNotFound(err) {
+		t.Errorf("Set(unknown) error = %v, want a not found error", err)
+	}
+}
+
+func testRemove(t *testing.T, clients client.Repo, users user.Repo) {
+	c := usertest.AddClient(t, clients, "acme")
+
+	stale := c.Version + 1
+	if err := clients.Remove(context.Background(), c.Id, &stale); !data.IsVersionMismatch(err) {
+		t.Errorf("Remove(stale version) error = %v, want a version mismatch error", err)
+	}
+
+	if err := clients.Remove(context.Background(), c.Id, &c.Version); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clients.Get(context.Background(), c.Id); !data.IsNotFound(err) {
+		t.Errorf("Get(removed) error = %v, want a not found error", err)
+	}
+	if err := clients.Remove(context.Background(), c.Id, nil); !data.IsNotFound(err) {
+		t.Errorf("Remove(removed) error = %v, want a not found error", err)
+	}
+}
+
+func testRemoveMemberships(t *testing.T, clients client.Repo, users user.Repo) {
+	a, b := usertest.AddClient(t, clients, "a"), usertest.AddClient(t, clients, "b")
+	u := usertest.AddUser(t, users, "a@example.com",
+		user.Membership{ClientId: a.Id, Role: user.RoleAdmin},
+		user.Membership{ClientId: b.Id, Role: user.RoleViewer},
+	)
+
+	if err := clients.Remove(context.Background(), a.Id, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := users.Get(context.Background(), u.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Memberships) != 1 || !got.Memberships[0].ClientId.Equal(b.Id) || got.Memberships[0].Role != user.RoleViewer {
+		t.Errorf("memberships = %v, want only the remaining client", got.Memberships)
+	}
+}
+
+func testList(t *testing.T, clients client.Repo, users user.Repo) {
+	for _, name := range []string{"c", "a", "b"} {
+		usertest.AddClient(t, clients, name)
+	}
+
+	list, err := clients.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireNames(t, list, "a", "b", "c")
+}
+
+func testListPage(t *testing.T, clients client.Repo, users user.Repo) {
+	for i := 4; i >= 0; i-- {
+		usertest.AddClient(t, clients, fmt.Sprint(i))
+	}
+
+	page := data.PageRequest{Limit: 2}
+	for _, want := range [][]string{{"0", "1"}, {"2", "3"}, {"4"}} {
+		list, p, err := clients.ListPage(context.Background(), client.Criteria{}, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		requireNames(t, list, want...)
+
+		if p.Total != 5 {
+			t.Errorf("Total = %d, want 5", p.Total)
+		}
+		if last := want[len(want)-1]; (p.Next == "") != (last == "4") {
+			t.Errorf("Next = %q after %s", p.Next, last)
+		}
+		page.Cursor = p.Next
+	}
+}
+
+func testListPageCriteria(t *testing.T, clients client.Repo, users user.Repo) {
+	used := usertest.AddClient(t, clients, "acme used")
+	usertest.AddClient(t, clients, "acme unused")
+	usertest.AddClient(t, clients, "other")
+	usertest.AddUser(t, users, "a@example.com", user.Membership{ClientId: used.Id, Role: user.DefaultRole})
+
+	yes, no := true, false
+
+	tests := []struct {
+		name     string
+		criteria client.Criteria
+		want     []string
+	}{
+		{"zero", client.Criteria{}, []string{"acme unused", "acme used", "other"}},
+		{"NamePrefix", client.Criteria{NamePrefix: "acme"}, []string{"acme unused", "acme used"}},
+		{"HasUsers", client.Criteria{HasUsers: &yes}, []string{"acme used"}},
+		{"not HasUsers", client.Criteria{HasUsers: &no}, []string{"acme unused", "other"}},
+		{"all", client.Criteria{NamePrefix: "o", HasUsers: &yes}, nil},
+	}
+	for _, test := range tests {
+		list, p, err := clients.ListPage(context.Background(), test.criteria, data.PageRequest{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		requireNames(t, list, test.want...)
+		if p.Total != len(test.want) {
+			t.Errorf("%s: Total = %d, want %d", test.name, p.Total, len(test.want))
+		}
+	}
+}
+
+func testListByIds(t *testing.T, clients client.Repo, users user.Repo) {
+	a, b := usertest.AddClient(t, clients, "a"), usertest.AddClient(t, clients, "b")
+	usertest.AddClient(t, clients, "c")
+
+	list, err := clients.ListByIds(context.Background(), []data.Id{b.Id, usertest.NewId(t), a.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireNames(t, list, "a", "b")
+
+	list, err = clients.ListByIds(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireNames(t, list)
+}
+
+//requireClient fails t if got is not equal to want.
+func requireClient(t *testing.T, got, want *client.Client) {
+	t.Helper()
+
+	if !got.Id.Equal(want.Id) ||
+		got.Name != want.Name ||
+		!got.CreatedAt.Equal(want.CreatedAt) ||
+		!got.UpdatedAt.Equal(want.UpdatedAt) ||
+		got.Version != want.Version {
+		t.Fatalf("client = %+v, want %+v", got, want)
+	}
+}
+
+//requireNames fails t if the Names of clients are not names in order.
+func requireNames(t *testing.T, clients []*client.Client, names ...string) {
+	t.Helper()
+
+	got := make([]string, len(clients))
+	for i, c := range clients {
+		got[i] = c.Name
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(names) || len(got) != len(names) {
+		t.Fatalf("names = %v, want %v", got, names)
+	}
+}
@@ -0,0 +1,13 @@
+package client
+
+//Criteria restricts which Clients are listed.
+//Each non-zero field must be satisfied by a Client for it to be listed.
+//The zero value matches all Clients.
+type Criteria struct {
+	//NamePrefix, if not empty, must be a prefix of the Client's Name.
+	NamePrefix string
+
+	//HasUsers, if not nil, indicates whether or not at least one User must have
+	//access to the Client.
+	HasUsers *bool
+}
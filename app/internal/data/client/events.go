@@ -0,0 +1,49 @@
+package client
+
+import "github.com/AgencyPMG/go-from-scratch/app/internal/data"
+
+//Names of the events emitted for Clients.
+const (
+	EventCreated = "client.created"
+	EventRenamed = "client.renamed"
+	EventDeleted = "client.deleted"
+)
+
+//ClientCreated is the event emitted after a Client is created.
+type ClientCreated struct {
+	//Client is the created Client.
+	Client Client
+}
+
+//EventName is the event.Event implementation.
+func (e ClientCreated) EventName() string {
+	return EventCreated
+}
+
+//ClientRenamed is the event emitted after a Client's Name is changed.
+type ClientRenamed struct {
+	//ClientId is the Id of the renamed Client.
+	ClientId data.Id
+
+	//OldName is the Client's Name before it was renamed.
+	OldName string
+
+	//NewName is the Client's Name after it was renamed.
+	NewName string
+}
+
+//EventName is the event.Event implementation.
+func (e ClientRenamed) EventName() string {
+	return EventRenamed
+}
+
+//ClientDeleted is the event emitted after a Client is deleted.
+type ClientDeleted struct {
+	//ClientId is the Id of the deleted Client.
+	ClientId data.Id
+}
+
+//EventName is the event.Event implementation.
+func (e ClientDeleted) EventName() string {
+	return EventDeleted
+}
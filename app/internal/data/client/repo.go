@@ -9,11 +9,21 @@ import (
 //QueryRepo provides the methods for retrieving Clients.
 type QueryRepo interface {
 	//Get should return the Client whose Id equals id.
+	//A *data.NotFoundError should be returned if the Client does not exist.
 	Get(ctx context.Context, id data.Id) (*Client, error)
 
 	//List should return all Clients in the repository.
+	//They should be sorted by their lexicographic Name order.
 	List(ctx context.Context) ([]*Client, error)
 
+	//ListPage should return the page of Clients that match criteria described
+	//by page in the same order as List.
+	//The Page's Total is the number of Clients that match criteria.
+	//The Page's Next cursor is keyed on the Name of the last Client.
+	//
+	//data.ErrInvalidCursor should be returned if page.Cursor is invalid.
+	ListPage(ctx context.Context, criteria Criteria, page data.PageRequest) ([]*Client, data.Page, error)
+
 	//ListById should return all Clients whose Id is in ids.
 	ListByIds(ctx context.Context, ids []data.Id) ([]*Client, error)
 }
@@ -24,12 +34,25 @@ type Repo interface {
 	QueryRepo
 
 	//Add should add c to the underlying repo's storage.
+	//A *data.ConflictError should be returned if a unique field of c is already taken.
 	Add(ctx context.Context, c Client) error
 
 	//Set should udpate all stored fields of c in the underlying storage repository.
 	//The update should use c.Id to determine with entity to update.
+	//
+	//The update should only happen if the stored version equals c.Version, and it
+	//should increment the stored version by one.
+	//A *data.VersionMismatchError should be returned if the stored version differs,
+	//and a *data.NotFoundError should be returned if the Client does not exist.
+	//A *data.ConflictError should be returned if a unique field of c is already taken.
 	Set(ctx context.Context, c Client) error
 
 	//Remove should remove the Client with id from the underlying storage.
-	Remove(ctx context.Context, id data.Id) error
+	//If version is not nil, the Client should only be removed if its stored
+	//version equals *version, in the same statement that removes it.
+	//A *data.NotFoundError should be returned if the Client does not exist, and a
+	//*data.VersionMismatchError should be returned if its version differs.
+	//Any remaining user relationships of the Client are removed along with it
+	//without updating the Users, so callers should remove those first.
+	Remove(ctx context.Context, id data.Id, version *int) error
 }
@@ -0,0 +1,123 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+)
+
+//ErrNotFound is a sentinel error indicating that a requested entity does not exist.
+//Repositories should prefer returning a *NotFoundError that describes what was
+//not found.
+var ErrNotFound = errors.New("data: not found")
+
+//NotFoundError is an error indicating that the entity of type Entity whose Field
+//equals Value does not exist.
+type NotFoundError struct {
+	//Entity is the name of the entity type, e.g. "user".
+	Entity string
+
+	//Field is the name of the field used to look up the entity, e.g. "id".
+	Field string
+
+	//Value is the value of Field that was looked up.
+	Value string
+}
+
+//NewNotFoundError returns a new *NotFoundError for entity whose field equals value.
+func NewNotFoundError(entity, field string, value interface{}) *NotFoundError {
+	return &NotFoundError{
+		Entity: entity,
+		Field:  field,
+		Value:  fmt.Sprint(value),
+	}
+}
+
+//Error is the error implementation.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("data: %s with %s %q not found", e.Entity, e.Field, e.Value)
+}
+
+//IsNotFound returns whether or not err is ErrNotFound or a *NotFoundError.
+func IsNotFound(err error) bool {
+	if err == ErrNotFound {
+		return true
+	}
+	_, ok := err.(*NotFoundError)
+	return ok
+}
+
+//ErrConflict is a sentinel error indicating that an entity could not be stored
+//because it conflicts with another, e.g. a unique field's value is already taken.
+//Repositories should prefer returning a *ConflictError that describes the conflict.
+var ErrConflict = errors.New("data: conflict")
+
+//ConflictError is an error indicating that an entity of type Entity could not be
+//stored because its Field's value is already used by another entity.
+type ConflictError struct {
+	//Entity is the name of the entity type, e.g. "user".
+	Entity string
+
+	//Field is the name of the field whose value conflicts, e.g. "email".
+	Field string
+}
+
+//NewConflictError returns a new *ConflictError for entity's field.
+func NewConflictError(entity, field string) *ConflictError {
+	return &ConflictError{
+		Entity: entity,
+		Field:  field,
+	}
+}
+
+//Error is the error implementation.
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("data: %s with the same %s already exists", e.Entity, e.Field)
+}
+
+//IsConflict returns whether or not err is ErrConflict or a *ConflictError.
+func IsConflict(err error) bool {
+	if err == ErrConflict {
+		return true
+	}
+	_, ok := err.(*ConflictError)
+	return ok
+}
+
+//ErrVersionMismatch is a sentinel error indicating that an entity could not be
+//changed because it is not at the expected version, i.e. it was changed by
+//someone else in the meantime.
+//Repositories and command handlers should prefer returning a *VersionMismatchError.
+var ErrVersionMismatch = errors.New("data: version mismatch")
+
+//VersionMismatchError is an error indicating that the entity of type Entity with
+//Id could not be changed because it is not at the expected version.
+type VersionMismatchError struct {
+	//Entity is the name of the entity type, e.g. "user".
+	Entity string
+
+	//Id is the Id of the entity.
+	Id Id
+}
+
+//NewVersionMismatchError returns a new *VersionMismatchError for the entity with id.
+func NewVersionMismatchError(entity string, id Id) *VersionMismatchError {
+	return &VersionMismatchError{
+		Entity: entity,
+		Id:     id,
+	}
+}
+
+//Error is the error implementation.
+func (e *VersionMismatchError) Error() string {
+	return fmt.Sprintf("data: %s with id %q is not at the expected version", e.Entity, e.Id)
+}
+
+//IsVersionMismatch returns whether or not err is ErrVersionMismatch or a
+//*VersionMismatchError.
+func IsVersionMismatch(err error) bool {
+	if err == ErrVersionMismatch {
+		return true
+	}
+	_, ok := err.(*VersionMismatchError)
+	return ok
+}
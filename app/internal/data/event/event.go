@@ -0,0 +1,96 @@
+package event
+
+import (
+	"context"
+	"sync"
+)
+
+//All is the event name to subscribe to in order to receive every published Event.
+const All = "*"
+
+//Event is a domain event describing something that happened in the application.
+type Event interface {
+	//EventName should return the name of the event, e.g. "user.created".
+	EventName() string
+}
+
+//Publisher publishes Events.
+type Publisher interface {
+	//Publish should deliver events, in order, to interested Subscribers.
+	Publish(ctx context.Context, events ...Event) error
+}
+
+//Subscriber handles published Events.
+type Subscriber interface {
+	//HandleEvent is called with each Event the Subscriber is subscribed to.
+	//Ctx is the context the Event was published with.
+	//
+	//A non-nil error indicates the Event was not handled and should be
+	//published again, so Subscribers should tolerate receiving an Event more than once.
+	HandleEvent(ctx context.Context, e Event) error
+}
+
+//SubscriberFunc is a function that implements Subscriber.
+type SubscriberFunc func(ctx context.Context, e Event) error
+
+//HandleEvent is the Subscriber implementation that calls f(ctx, e).
+func (f SubscriberFunc) HandleEvent(ctx context.Context, e Event) error {
+	return f(ctx, e)
+}
+
+//Bus is an in-process Publisher that delivers Events synchronously to the
+//Subscribers registered with Subscribe.
+//
+//The zero value is ready to use, and it is safe for use by multiple goroutines.
+type Bus struct {
+	mu sync.RWMutex
+
+	subscribers map[string][]Subscriber
+}
+
+//Subscribe registers s to receive all published Events with name.
+//Name may be All to receive every Event.
+func (b *Bus) Subscribe(name string, s Subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subscribers == nil {
+		b.subscribers = map[string][]Subscriber{}
+	}
+	b.subscribers[name] = append(b.subscribers[name], s)
+}
+
+//Publish is the Publisher implementation.
+//Each Event is delivered to the Subscribers of its name and then to the
+//Subscribers of All, in the order they subscribed.
+//
+//Every Subscriber receives each Event even if another Subscriber fails, and the
+//first error from a Subscriber is returned after the Event is delivered.
+//Later events are not delivered after an error.
+//ctx.Err() is returned if ctx is done before all events are delivered.
+func (b *Bus) Publish(ctx context.Context, events ...Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, e := range events {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var first error
+		named, all := b.subscribers[e.EventName()], b.subscribers[All]
+
+		subscribers := make([]Subscriber, 0, len(named)+len(all))
+		subscribers = append(append(subscribers, named...), all...)
+		for _, s := range subscribers {
+			if err := s.HandleEvent(ctx, e); err != nil && first == nil {
+				first = err
+			}
+		}
+		if first != nil {
+			return first
+		}
+	}
+
+	return nil
+}
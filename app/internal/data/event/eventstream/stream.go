@@ -0,0 +1,303 @@
+package eventstream
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event"
+)
+
+const (
+	//DefaultSize is the number of recent Notifications kept by a Stream for
+	//resuming if no Size is set.
+	DefaultSize = 1024
+
+	//listenerBuffer is the number of Notifications that can be waiting for a
+	//Listener before it is closed for being too slow.
+	listenerBuffer = 256
+)
+
+//Notification is a published Event along with the entity information used to
+//filter it.
+type Notification struct {
+	//Id is the Notification's position in the Stream.
+	//Ids increase with each Notification.
+	Id uint64
+
+	//Event is the published Event.
+	Event event.Event
+
+	//Entity is the name of the type of entity the Event is about, e.g. user.EntityName.
+	Entity string
+
+	//EntityId is the Id of the entity the Event is about.
+	EntityId data.Id
+
+	//ClientIds is the Ids of the Clients related to the entity.
+	ClientIds []data.Id
+
+	//CreatedAt is when the Notification was added to the Stream.
+	CreatedAt time.Time
+}
+
+//Filter determines the Notifications a Listener receives.
+//The zero value matches every Notification.
+type Filter struct {
+	//Entities, if not empty, is the entity names to match.
+	Entities []string
+
+	//ClientId, if not nil, must be one of the Notification's ClientIds.
+	ClientId *data.Id
+}
+
+//Matches returns whether or not n satisfies f.
+func (f Filter) Matches(n *Notification) bool {
+	if len(f.Entities) > 0 && !containsString(f.Entities, n.Entity) {
+		return false
+	}
+
+	if f.ClientId != nil && !containsId(n.ClientIds, *f.ClientId) {
+		return false
+	}
+
+	return true
+}
+
+//Stream is an event.Subscriber that keeps recent Notifications in memory and
+//sends new ones to Listeners.
+//
+//Stream is safe for use by multiple goroutines.
+//Its Describe field must be set before use.
+type Stream struct {
+	//Describe returns the Notification for e with its Entity, EntityId, and
+	//ClientIds set, or false if e should not be streamed.
+	Describe func(e event.Event) (Notification, bool)
+
+	//Size is the number of recent Notifications kept for Listeners resuming
+	//after a disconnect.
+	Size int
+
+	mu sync.Mutex
+
+	//recent is a ring buffer of the most recent Notifications, oldest first
+	//starting at index start.
+	recent []*Notification
+	start  int
+
+	//lastId is the Id of the most recent Notification.
+	lastId uint64
+
+	listeners map[*Listener]struct{}
+
+	closed bool
+}
+
+//HandleEvent is the event.Subscriber implementation.
+//It adds a Notification for e and sends it to all matching Listeners.
+//Listeners that cannot keep up are closed instead of blocking the publisher.
+func (s *Stream) HandleEvent(ctx context.Context, e event.Event) error {
+	n, ok := s.Describe(e)
+	if !ok {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.init()
+
+	s.lastId++
+	n.Id = s.lastId
+	n.Event = e
+	n.CreatedAt = time.Now().UTC()
+
+	s.add(&n)
+
+	for l := range s.listeners {
+		if !l.filter.Matches(&n) {
+			continue
+		}
+
+		select {
+		case l.c <- &n:
+		default:
+			s.remove(l)
+		}
+	}
+
+	return nil
+}
+
+//Listen returns a new Listener that receives the Notifications matching filter
+//that are added after this call.
+//
+//If lastId is not 0, the recent Notifications after lastId that match filter are
+//also returned, and complete is false if some Notifications after lastId are no
+//longer kept or lastId is not from this Stream.
+//
+//The Listener must be closed when it is no longer needed.
+func (s *Stream) Listen(lastId uint64, filter Filter) (l *Listener, missed []*Notification, complete bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.init()
+
+	l = &Listener{
+		stream: s,
+		filter: filter,
+		since:  s.lastId,
+		c:      make(chan *Notification, listenerBuffer),
+	}
+
+	if s.closed {
+		close(l.c)
+		return l, nil, true
+	}
+	s.listeners[l] = struct{}{}
+
+	if lastId == 0 {
+		return l, nil, true
+	}
+
+	//The first kept Notification must directly follow lastId for nothing to be
+	//lost.
+	complete = lastId <= s.lastId && lastId+uint64(len(s.recent)) >= s.lastId
+	for i := range s.recent {
+		n := s.recent[(s.start+i)%len(s.recent)]
+		if n.Id > lastId && filter.Matches(n) {
+			missed = append(missed, n)
+		}
+	}
+
+	return l, missed, complete
+}
+
+//LastId returns the Id of the most recent Notification in s.
+func (s *Stream) LastId() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.init()
+
+	return s.lastId
+}
+
+//Run waits for ctx to be done and then closes all of s' Listeners.
+//Listeners created after Run returns are already closed.
+//It always returns ctx.Err().
+//
+//Run allows a Stream to be run alongside a server so long lived responses end
+//when the server shuts down.
+func (s *Stream) Run(ctx context.Context) error {
+	<-ctx.Done()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.init()
+
+	s.closed = true
+	for l := range s.listeners {
+		s.remove(l)
+	}
+
+	return ctx.Err()
+}
+
+//init initializes s' internal state if it has not been already.
+//s.mu must be held.
+//
+//Ids start at the current unix time in microseconds so that they keep
+//increasing across restarts, and a lastId from before a restart is never
+//mistaken for one of the current Notifications.
+func (s *Stream) init() {
+	if s.listeners != nil {
+		return
+	}
+
+	s.listeners = map[*Listener]struct{}{}
+	s.lastId = uint64(time.Now().UnixNano() / int64(time.Microsecond))
+}
+
+//add adds n to the ring buffer of recent Notifications.
+//s.mu must be held.
+func (s *Stream) add(n *Notification) {
+	size := s.Size
+	if size <= 0 {
+		size = DefaultSize
+	}
+
+	if len(s.recent) < size {
+		s.recent = append(s.recent, n)
+		return
+	}
+
+	s.recent[s.start] = n
+	s.start = (s.start + 1) % len(s.recent)
+}
+
+//remove stops sending to l and closes its channel.
+//s.mu must be held.
+func (s *Stream) remove(l *Listener) {
+	if _, ok := s.listeners[l]; !ok {
+		return
+	}
+
+	delete(s.listeners, l)
+	close(l.c)
+}
+
+//Listener receives Notifications from a Stream.
+type Listener struct {
+	stream *Stream
+
+	filter Filter
+
+	//since is the Id of the Stream's most recent Notification when l was created.
+	since uint64
+
+	c chan *Notification
+}
+
+//Since returns the Id of the Stream's most recent Notification when l was
+//created.
+//L receives the matching Notifications after it.
+func (l *Listener) Since() uint64 {
+	return l.since
+}
+
+//C returns the channel Notifications are received on.
+//It is closed when l is closed, when l falls too far behind the Stream, or when
+//the Stream stops running.
+func (l *Listener) C() <-chan *Notification {
+	return l.c
+}
+
+//Close stops l from receiving Notifications.
+//It is safe to call Close more than once.
+func (l *Listener) Close() {
+	l.stream.mu.Lock()
+	defer l.stream.mu.Unlock()
+
+	l.stream.remove(l)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsId(ids []data.Id, id data.Id) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,305 @@
+package eventstream_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event/eventstream"
+)
+
+const (
+	entityUser   = "user"
+	entityClient = "client"
+)
+
+//testEvent is an event.Event that describes itself as a Notification.
+type testEvent struct {
+	name      string
+	entity    string
+	clientIds []data.Id
+}
+
+func (e testEvent) EventName() string {
+	return e.name
+}
+
+func TestStream_Listen_new(t *testing.T) {
+	s := newStream(0)
+	publish(t, s, testEvent{name: "a", entity: entityUser})
+
+	l, missed, complete := s.Listen(0, eventstream.Filter{})
+	defer l.Close()
+
+	if len(missed) != 0 || !complete {
+		t.Fatalf("Listen(0) = %v, %v, want nothing missed", names(missed), complete)
+	}
+	if l.Since() != s.LastId() {
+		t.Errorf("Since() = %d, want %d", l.Since(), s.LastId())
+	}
+
+	publish(t, s, testEvent{name: "b", entity: entityUser})
+	requireReceived(t, l, "b")
+}
+
+func TestStream_Listen_nothingMissed(t *testing.T) {
+	s := newStream(0)
+	publish(t, s, testEvent{name: "a", entity: entityUser})
+
+	l, missed, complete := s.Listen(s.LastId(), eventstream.Filter{})
+	defer l.Close()
+
+	if len(missed) != 0 || !complete {
+		t.Fatalf("Listen(LastId()) = %v, %v, want nothing missed", names(missed), complete)
+	}
+}
+
+func TestStream_Listen_missed(t *testing.T) {
+	s := newStream(0)
+	lastId := s.LastId()
+	publish(t, s,
+		testEvent{name: "a", entity: entityUser},
+		testEvent{name: "b", entity: entityClient},
+		testEvent{name: "c", entity: entityUser},
+	)
+
+	l, missed, complete := s.Listen(lastId+1, eventstream.Filter{})
+	defer l.Close()
+
+	if got := names(missed); got != "[b c]" || !complete {
+		t.Fatalf("Listen() = %v, %v, want [b c], true", got, complete)
+	}
+	for i, n := range missed {
+		if want := lastId + 2 + uint64(i); n.Id != want {
+			t.Errorf("missed[%d].Id = %d, want %d", i, n.Id, want)
+		}
+	}
+}
+
+func TestStream_Listen_missedFilter(t *testing.T) {
+	clientId, otherId := newId(t), newId(t)
+
+	s := newStream(0)
+	lastId := s.LastId()
+	publish(t, s,
+		testEvent{name: "a", entity: entityUser, clientIds: []data.Id{clientId}},
+		testEvent{name: "b", entity: entityClient, clientIds: []data.Id{clientId}},
+		testEvent{name: "c", entity: entityUser, clientIds: []data.Id{otherId}},
+		testEvent{name: "d", entity: entityUser},
+		testEvent{name: "e", entity: entityUser, clientIds: []data.Id{otherId, clientId}},
+	)
+
+	tests := []struct {
+		name   string
+		filter eventstream.Filter
+		want   string
+	}{
+		{"zero", eventstream.Filter{}, "[a b c d e]"},
+		{"Entities", eventstream.Filter{Entities: []string{entityUser}}, "[a c d e]"},
+		{"ClientId", eventstream.Filter{ClientId: &clientId}, "[a b e]"},
+		{"all", eventstream.Filter{Entities: []string{entityUser}, ClientId: &clientId}, "[a e]"},
+		{"none", eventstream.Filter{Entities: []string{"other"}}, "[]"},
+	}
+	for _, test := range tests {
+		l, missed, complete := s.Listen(lastId, test.filter)
+		l.Close()
+
+		//Notifications that do not match are not missing.
+		if got := names(missed); got != test.want || !complete {
+			t.Errorf("%s: Listen() = %v, %v, want %v, true", test.name, got, complete, test.want)
+		}
+	}
+}
+
+func TestStream_Listen_wrapped(t *testing.T) {
+	s := newStream(3)
+	lastId := s.LastId()
+	publish(t, s,
+		testEvent{name: "a", entity: entityUser},
+		testEvent{name: "b", entity: entityUser},
+		testEvent{name: "c", entity: entityUser},
+		testEvent{name: "d", entity: entityUser},
+		testEvent{name: "e", entity: entityUser},
+	)
+
+	tests := []struct {
+		lastId   uint64
+		want     string
+		complete bool
+	}{
+		{lastId, "[c d e]", false},
+		{lastId + 1, "[c d e]", false},
+		{lastId + 2, "[c d e]", true},
+		{lastId + 3, "[d e]", true},
+		{lastId + 5, "[]", true},
+	}
+	for _, test := range tests {
+		l, missed, complete := s.Listen(test.lastId, eventstream.Filter{})
+		l.Close()
+
+		if got := names(missed); got != test.want || complete != test.complete {
+			t.Errorf("Listen(lastId + %d) = %v, %v, want %v, %v", test.lastId-lastId, got, complete, test.want, test.complete)
+		}
+	}
+}
+
+func TestStream_Listen_future(t *testing.T) {
+	s := newStream(0)
+	publish(t, s, testEvent{name: "a", entity: entityUser})
+
+	l, missed, complete := s.Listen(s.LastId()+1, eventstream.Filter{})
+	defer l.Close()
+
+	if len(missed) != 0 || complete {
+		t.Errorf("Listen(future) = %v, %v, want nothing and incomplete", names(missed), complete)
+	}
+}
+
+func TestStream_Listen_restarted(t *testing.T) {
+	before := newStream(0)
+	publish(t, before,
+		testEvent{name: "a", entity: entityUser},
+		testEvent{name: "b", entity: entityUser},
+	)
+	lastId := before.LastId()
+
+	//Ids are seeded from the clock in microseconds.
+	time.Sleep(time.Millisecond)
+
+	s := newStream(0)
+	if s.LastId() <= lastId {
+		t.Fatalf("LastId() = %d after restarting, want more than %d", s.LastId(), lastId)
+	}
+
+	l, missed, complete := s.Listen(lastId, eventstream.Filter{})
+	l.Close()
+	if len(missed) != 0 || complete {
+		t.Errorf("Listen(before restart) = %v, %v, want nothing and incomplete", names(missed), complete)
+	}
+
+	publish(t, s, testEvent{name: "c", entity: entityUser})
+
+	l, missed, complete = s.Listen(lastId, eventstream.Filter{})
+	l.Close()
+	if got := names(missed); got != "[c]" || complete {
+		t.Errorf("Listen(before restart) = %v, %v, want [c] and incomplete", got, complete)
+	}
+}
+
+func TestStream_HandleEvent_slowListener(t *testing.T) {
+	s := newStream(0)
+
+	slow, _, _ := s.Listen(0, eventstream.Filter{})
+	defer slow.Close()
+	other, _, _ := s.Listen(0, eventstream.Filter{Entities: []string{entityClient}})
+	defer other.Close()
+
+	//Enough Notifications to fill any reasonable buffer.
+	for i := 0; i < eventstream.DefaultSize; i++ {
+		publish(t, s, testEvent{name: "a", entity: entityUser})
+	}
+
+	received := 0
+	for range slow.C() {
+		received++
+	}
+	if received == 0 || received >= eventstream.DefaultSize {
+		t.Errorf("received %d Notifications before being closed, want some but not all", received)
+	}
+
+	//Listeners that do not match are not closed.
+	publish(t, s, testEvent{name: "b", entity: entityClient})
+	requireReceived(t, other, "b")
+}
+
+func TestStream_Run(t *testing.T) {
+	s := newStream(0)
+	l, _, _ := s.Listen(0, eventstream.Filter{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.Run(ctx); err != context.Canceled {
+		t.Errorf("Run() = %v, want %v", err, context.Canceled)
+	}
+
+	if _, ok := <-l.C(); ok {
+		t.Error("Listener is open after Run returned")
+	}
+
+	after, _, _ := s.Listen(0, eventstream.Filter{})
+	if _, ok := <-after.C(); ok {
+		t.Error("Listener is open after Run returned")
+	}
+	l.Close()
+}
+
+//newStream returns a new Stream that keeps size Notifications of testEvents.
+func newStream(size int) *eventstream.Stream {
+	return &eventstream.Stream{
+		Describe: func(e event.Event) (eventstream.Notification, bool) {
+			te, ok := e.(testEvent)
+			if !ok {
+				return eventstream.Notification{}, false
+			}
+			return eventstream.Notification{
+				Entity:    te.entity,
+				ClientIds: te.clientIds,
+			}, true
+		},
+		Size: size,
+	}
+}
+
+//publish sends events to s in order.
+func publish(t *testing.T, s *eventstream.Stream, events ...testEvent) {
+	t.Helper()
+
+	for _, e := range events {
+		if err := s.HandleEvent(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+//requireReceived fails t if the next Notification l receives is not for the
+//event with name.
+func requireReceived(t *testing.T, l *eventstream.Listener, name string) {
+	t.Helper()
+
+	select {
+	case n, ok := <-l.C():
+		if !ok {
+			t.Fatalf("Listener closed, want %s", name)
+		}
+		if got := n.Event.EventName(); got != name {
+			t.Fatalf("received %s, want %s", got, name)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("received nothing, want %s", name)
+	}
+}
+
+//names returns the names of the Events of notifications, formatted like a
+//slice.
+func names(notifications []*eventstream.Notification) string {
+	result := "["
+	for i, n := range notifications {
+		if i > 0 {
+			result += " "
+		}
+		result += n.Event.EventName()
+	}
+	return result + "]"
+}
+
+func newId(t *testing.T) data.Id {
+	t.Helper()
+
+	id, err := data.NewId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
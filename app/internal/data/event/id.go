@@ -0,0 +1,25 @@
+package event
+
+import (
+	"context"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//idKey is the context key for the Id of the Event being handled.
+type idKey struct{}
+
+//ContextWithId returns a copy of ctx that records id as the Id of the Event
+//published with it.
+//Publishers that store Events, such as an outbox, use it so that Subscribers can
+//identify the same Event when it is delivered more than once.
+func ContextWithId(ctx context.Context, id data.Id) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+//IdFromContext returns the Id stored in ctx by ContextWithId and whether or not
+//there is one.
+func IdFromContext(ctx context.Context) (data.Id, bool) {
+	id, ok := ctx.Value(idKey{}).(data.Id)
+	return id, ok
+}
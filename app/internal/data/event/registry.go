@@ -0,0 +1,47 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+//ErrUnknownEvent is a sentinel error indicating that an event name was never
+//registered with a Registry.
+var ErrUnknownEvent = errors.New("event: unknown event")
+
+//Registry maps event names to their types so that Events can be decoded from
+//their names and JSON representations.
+//
+//The zero value is ready to use.
+//Registry is safe for concurrent decoding once all Events are registered.
+type Registry struct {
+	types map[string]reflect.Type
+}
+
+//Register registers the type of each of events under its name.
+//Events should be values, not pointers, and are decoded as the same type.
+func (r *Registry) Register(events ...Event) {
+	if r.types == nil {
+		r.types = map[string]reflect.Type{}
+	}
+	for _, e := range events {
+		r.types[e.EventName()] = reflect.TypeOf(e)
+	}
+}
+
+//Decode returns the Event registered under name unmarshaled from payload.
+//ErrUnknownEvent is returned if name is not registered.
+func (r *Registry) Decode(name string, payload []byte) (Event, error) {
+	t, ok := r.types[name]
+	if !ok {
+		return nil, ErrUnknownEvent
+	}
+
+	v := reflect.New(t)
+	if err := json.Unmarshal(payload, v.Interface()); err != nil {
+		return nil, err
+	}
+
+	return v.Elem().Interface().(Event), nil
+}
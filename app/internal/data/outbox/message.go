@@ -0,0 +1,45 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//EntityName is the name used to refer to Message entities in errors and other
+//descriptions.
+const EntityName = "outbox message"
+
+//Message is an event that has been published but not necessarily delivered to
+//the application's Subscribers.
+type Message struct {
+	//Id is the Message's id.
+	Id data.Id
+
+	//Event is the name of the event, e.g. "user.created".
+	Event string
+
+	//Payload is the JSON representation of the event.
+	Payload json.RawMessage
+
+	//CreatedAt is the time at which the event was published.
+	//Messages are delivered in CreatedAt order.
+	CreatedAt time.Time
+
+	//DispatchedAt is the time at which the event was delivered, or nil if it
+	//has not been delivered.
+	DispatchedAt *time.Time
+
+	//Attempts is the number of times delivering the event has failed.
+	Attempts int
+
+	//LastError describes why the last attempt failed.
+	//It is empty if there have been no failed attempts.
+	LastError string
+
+	//FailedAt is the time at which the event was given up on, or nil if it is
+	//still being delivered.
+	//Failed Messages are no longer pending.
+	FailedAt *time.Time
+}
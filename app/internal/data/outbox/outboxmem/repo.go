@@ -0,0 +1,114 @@
+package outboxmem
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/outbox"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/memrepo"
+)
+
+var _ outbox.Repo = &Repo{} //Ensure *Repo is an outbox.Repo.
+
+//Table is the memrepo table we store our outbox messages in.
+const Table = "outbox"
+
+//Repo is an outbox.Repo implementation that stores Messages in memory.
+type Repo struct {
+	mem *memrepo.Repo
+}
+
+//New returns a new Repo that uses repo as its storage.
+func New(repo *memrepo.Repo) *Repo {
+	return &Repo{
+		mem: repo,
+	}
+}
+
+//Add is the outbox.Repo implementation.
+func (r *Repo) Add(ctx context.Context, messages ...outbox.Message) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		for _, m := range messages {
+			if _, ok := tx.Get(Table, m.Id); ok {
+				return data.NewConflictError(outbox.EntityName, "id")
+			}
+			tx.Put(Table, m.Id, m)
+		}
+		return nil
+	})
+}
+
+//ListPending is the outbox.Repo implementation.
+func (r *Repo) ListPending(ctx context.Context, limit int) ([]*outbox.Message, error) {
+	result := []*outbox.Message{}
+
+	err := r.mem.ReadWorkContext(ctx, func(tx *memrepo.Tx) error {
+		for _, row := range tx.Rows(Table) {
+			m := row.(outbox.Message)
+			if m.DispatchedAt == nil && m.FailedAt == nil {
+				result = append(result, &m)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].Id.String() < result[j].Id.String()
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+//MarkDispatched is the outbox.Repo implementation.
+func (r *Repo) MarkDispatched(ctx context.Context, at time.Time, ids ...data.Id) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		for _, id := range ids {
+			row, ok := tx.Get(Table, id)
+			if !ok {
+				continue
+			}
+
+			m := row.(outbox.Message)
+			if m.DispatchedAt == nil {
+				m.DispatchedAt = &at
+				tx.Put(Table, id, m)
+			}
+		}
+		return nil
+	})
+}
+
+//MarkAttempted is the outbox.Repo implementation.
+func (r *Repo) MarkAttempted(ctx context.Context, at time.Time, id data.Id, reason string, failed bool) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		row, ok := tx.Get(Table, id)
+		if !ok {
+			return nil
+		}
+
+		m := row.(outbox.Message)
+		if m.DispatchedAt != nil {
+			return nil
+		}
+
+		m.Attempts++
+		m.LastError = reason
+		if failed {
+			m.FailedAt = &at
+		}
+		tx.Put(Table, id, m)
+
+		return nil
+	})
+}
@@ -0,0 +1,159 @@
+package outboxsql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/outbox"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/sqlrepo"
+)
+
+var _ outbox.Repo = &Repo{} //Ensure *Repo is an outbox.Repo.
+
+//Table is the table we store our outbox messages in.
+const Table = "outbox"
+
+//SelectFrom is our select query without filtering, ordering, etc.
+const SelectFrom = `SELECT
+	o.id,
+	o.event,
+	o.payload,
+	o.created_at,
+	o.dispatched_at,
+	o.attempts,
+	o.last_error,
+	o.failed_at
+	FROM ` + Table + ` AS o`
+
+//Repo is an outbox.Repo implementation that uses a SQL database as its storage.
+type Repo struct {
+	db *sqlrepo.Repo
+}
+
+//New returns a new Repo that uses repo to talk to the database.
+func New(repo *sqlrepo.Repo) *Repo {
+	return &Repo{
+		db: repo,
+	}
+}
+
+//Add is the outbox.Repo implementation.
+func (r *Repo) Add(ctx context.Context, messages ...outbox.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (id, event, payload, created_at) VALUES %s",
+		Table,
+		sqlrepo.List("(?,?,?,?)", len(messages)),
+	)
+
+	args := make([]interface{}, 0, 4*len(messages))
+	for _, m := range messages {
+		args = append(args, m.Id, m.Event, string(m.Payload), sqlrepo.UTCTime{m.CreatedAt})
+	}
+
+	_, err := r.db.ExecContext(ctx, query, args...)
+
+	return r.db.TranslateError(err, outbox.EntityName)
+}
+
+//ListPending is the outbox.Repo implementation.
+func (r *Repo) ListPending(ctx context.Context, limit int) ([]*outbox.Message, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		SelectFrom+" WHERE o.dispatched_at IS NULL AND o.failed_at IS NULL ORDER BY o.created_at ASC, o.id ASC LIMIT ?",
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*outbox.Message{}
+	for rows.Next() {
+		m, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+
+	return result, rows.Err()
+}
+
+//scan is a helper function to scan a single Message from a sql row or rows.
+func scan(s sqlrepo.Scanner) (*outbox.Message, error) {
+	m := &outbox.Message{}
+
+	var payload []byte
+	createdAt, dispatchedAt, failedAt := sqlrepo.UTCTime{}, sqlrepo.NullUTCTime{}, sqlrepo.NullUTCTime{}
+
+	if err := s.Scan(
+		&m.Id,
+		&m.Event,
+		&payload,
+		&createdAt,
+		&dispatchedAt,
+		&m.Attempts,
+		&m.LastError,
+		&failedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	m.Payload = payload
+	m.CreatedAt = createdAt.Time
+	if dispatchedAt.Valid {
+		m.DispatchedAt = &dispatchedAt.Time
+	}
+	if failedAt.Valid {
+		m.FailedAt = &failedAt.Time
+	}
+
+	return m, nil
+}
+
+//MarkDispatched is the outbox.Repo implementation.
+func (r *Repo) MarkDispatched(ctx context.Context, at time.Time, ids ...data.Id) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders, args := sqlrepo.IdsPlaceholdersArgs(ids)
+	args = append([]interface{}{sqlrepo.UTCTime{at}}, args...)
+
+	_, err := r.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			"UPDATE %s SET dispatched_at = ? WHERE dispatched_at IS NULL AND id IN (%s)",
+			Table,
+			placeholders,
+		),
+		args...,
+	)
+	return err
+}
+
+//MarkAttempted is the outbox.Repo implementation.
+func (r *Repo) MarkAttempted(ctx context.Context, at time.Time, id data.Id, reason string, failed bool) error {
+	failedAt := sqlrepo.NullUTCTime{}
+	if failed {
+		failedAt = sqlrepo.NullUTCTime{Time: at, Valid: true}
+	}
+
+	_, err := r.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			"UPDATE %s SET attempts = attempts + 1, last_error = ?, failed_at = ? WHERE dispatched_at IS NULL AND id = ?",
+			Table,
+		),
+		reason,
+		failedAt,
+		id,
+	)
+	return err
+}
@@ -0,0 +1,72 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event"
+)
+
+//Publisher is an event.Publisher that stores events as Messages for a Relay
+//to deliver.
+//
+//Publish should be called with the context of the transaction that made the
+//changes the events describe, so that they are stored or lost together.
+type Publisher struct {
+	//Messages is where published events are stored.
+	Messages Repo
+
+	mu sync.Mutex
+
+	//last is the CreatedAt time of the last Message created.
+	last time.Time
+}
+
+//Publish is the event.Publisher implementation.
+//Events are given increasing CreatedAt times so that they are delivered in the
+//order they are published.
+func (p *Publisher) Publish(ctx context.Context, events ...event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	messages := make([]Message, len(events))
+	for i, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+
+		id, err := data.NewId()
+		if err != nil {
+			return err
+		}
+
+		messages[i] = Message{
+			Id:        id,
+			Event:     e.EventName(),
+			Payload:   payload,
+			CreatedAt: p.now(),
+		}
+	}
+
+	return p.Messages.Add(ctx, messages...)
+}
+
+//now returns the current time with microsecond precision, so that it survives
+//a round trip through the database, that is always after the previous result.
+func (p *Publisher) now() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	if !now.After(p.last) {
+		now = p.last.Add(time.Microsecond)
+	}
+	p.last = now
+
+	return now
+}
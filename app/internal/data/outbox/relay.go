@@ -0,0 +1,168 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event"
+)
+
+const (
+	//DefaultRelayInterval is the Interval used by a Relay if none is set.
+	DefaultRelayInterval = time.Second
+
+	//DefaultRelayBatchSize is the BatchSize used by a Relay if none is set.
+	DefaultRelayBatchSize = 100
+
+	//DefaultRelayMaxAttempts is the MaxAttempts used by a Relay if none is set.
+	DefaultRelayMaxAttempts = 10
+)
+
+//Relay delivers pending Messages to a Publisher, usually an *event.Bus, and
+//marks them dispatched.
+//
+//Delivery is at least once: Messages are only marked dispatched after they are
+//delivered, so a Message is delivered again if the process stops in between.
+//Events are published with the Id of their Message in their context, see
+//event.ContextWithId.
+//
+//A Message that fails to be delivered does not hold up the Messages after it.
+//It is attempted again in later batches until it has failed MaxAttempts times,
+//after which it is marked failed and no longer pending.
+type Relay struct {
+	//Messages is where pending Messages are retrieved from.
+	Messages Repo
+
+	//Registry decodes Messages into the Events they were published from.
+	Registry *event.Registry
+
+	//Publisher is where decoded Events are delivered.
+	Publisher event.Publisher
+
+	//Interval is how often pending Messages are checked for.
+	Interval time.Duration
+
+	//BatchSize is the maximum number of Messages delivered at once.
+	BatchSize int
+
+	//MaxAttempts is the number of failed deliveries after which a Message is
+	//marked failed.
+	MaxAttempts int
+
+	//OnError, if not nil, is called with errors that occur while relaying.
+	OnError func(err error)
+}
+
+//Run relays pending Messages every r.Interval until ctx is done.
+//Full batches are relayed one after another without waiting.
+//It always returns ctx.Err().
+func (r *Relay) Run(ctx context.Context) error {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = DefaultRelayInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := r.RelayPending(ctx)
+			if err != nil && ctx.Err() == nil {
+				r.onError(err)
+			}
+			if err != nil || n < r.batchSize() {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+//RelayPending delivers a single batch of pending Messages and marks them
+//dispatched.
+//It returns the number of Messages delivered.
+//
+//Messages that fail to be delivered are reported to r.OnError and the rest of
+//the batch is still delivered.
+//Messages that cannot be decoded are marked failed immediately since they will
+//never be decoded.
+func (r *Relay) RelayPending(ctx context.Context) (int, error) {
+	messages, err := r.Messages.ListPending(ctx, r.batchSize())
+	if err != nil {
+		return 0, err
+	}
+
+	ids := make([]data.Id, 0, len(messages))
+	for _, m := range messages {
+		e, err := r.Registry.Decode(m.Event, m.Payload)
+		if err != nil {
+			if err := r.markAttempted(ctx, m, err, true); err != nil {
+				return len(ids), r.markDispatched(ctx, ids, err)
+			}
+			continue
+		}
+
+		if err := r.Publisher.Publish(event.ContextWithId(ctx, m.Id), e); err != nil {
+			//Stopping is not the Message's fault, so it is not counted as an attempt.
+			if ctx.Err() != nil {
+				return len(ids), r.markDispatched(ctx, ids, ctx.Err())
+			}
+			if err := r.markAttempted(ctx, m, err, m.Attempts+1 >= r.maxAttempts()); err != nil {
+				return len(ids), r.markDispatched(ctx, ids, err)
+			}
+			continue
+		}
+		ids = append(ids, m.Id)
+	}
+
+	return len(ids), r.markDispatched(ctx, ids, nil)
+}
+
+//markAttempted reports cause, the reason m failed to be delivered, to r.OnError
+//and records the failed attempt, marking m failed if failed is true.
+func (r *Relay) markAttempted(ctx context.Context, m *Message, cause error, failed bool) error {
+	r.onError(fmt.Errorf("outbox: delivering %s message %v: %v", m.Event, m.Id, cause))
+
+	return r.Messages.MarkAttempted(ctx, time.Now().UTC(), m.Id, cause.Error(), failed)
+}
+
+//markDispatched marks the Messages with ids dispatched.
+//The error returned is cause if it is not nil and marking succeeded.
+func (r *Relay) markDispatched(ctx context.Context, ids []data.Id, cause error) error {
+	if len(ids) > 0 {
+		//The Messages were delivered, so use a context that is not cancelled in
+		//order to avoid delivering them again.
+		if err := r.Messages.MarkDispatched(context.Background(), time.Now().UTC(), ids...); err != nil {
+			return err
+		}
+	}
+	return cause
+}
+
+func (r *Relay) batchSize() int {
+	if r.BatchSize <= 0 {
+		return DefaultRelayBatchSize
+	}
+	return r.BatchSize
+}
+
+func (r *Relay) maxAttempts() int {
+	if r.MaxAttempts <= 0 {
+		return DefaultRelayMaxAttempts
+	}
+	return r.MaxAttempts
+}
+
+func (r *Relay) onError(err error) {
+	if r.OnError != nil {
+		r.OnError(err)
+	}
+}
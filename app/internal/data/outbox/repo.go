@@ -0,0 +1,32 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//Repo provides methods for adding, retrieving, and dispatching Messages.
+type Repo interface {
+	//Add should add messages to the underlying storage repository.
+	//It should join the transaction in ctx, if any, so that Messages are only
+	//stored if the changes that caused them are.
+	Add(ctx context.Context, messages ...Message) error
+
+	//ListPending should return up to limit Messages that have not been dispatched
+	//or failed.
+	//They should be sorted by their CreatedAt time and then their Id.
+	ListPending(ctx context.Context, limit int) ([]*Message, error)
+
+	//MarkDispatched should set the DispatchedAt time of the Messages with ids to at.
+	//Ids that do not exist or are already dispatched should be ignored.
+	MarkDispatched(ctx context.Context, at time.Time, ids ...data.Id) error
+
+	//MarkAttempted should increment the Attempts of the Message with id and set
+	//its LastError to reason.
+	//If failed is true, it should also set the Message's FailedAt time to at so
+	//that it is no longer pending.
+	//An id that does not exist or is already dispatched should be ignored.
+	MarkAttempted(ctx context.Context, at time.Time, id data.Id, reason string, failed bool) error
+}
@@ -0,0 +1,126 @@
+package data
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	//DefaultPageLimit is the Limit that should be used for a PageRequest when
+	//none is specified.
+	DefaultPageLimit = 100
+
+	//MaxPageLimit is the largest allowed Limit of a PageRequest.
+	MaxPageLimit = 1000
+)
+
+//ErrInvalidCursor is a sentinel error indicating that a PageRequest's Cursor
+//was not created by this package.
+var ErrInvalidCursor = errors.New("data: invalid page cursor")
+
+//ErrInvalidPageLimit is a sentinel error indicating that a PageRequest's Limit
+//is not between 1 and MaxPageLimit.
+var ErrInvalidPageLimit = errors.New("data: invalid page limit")
+
+//PageRequest describes a single page of entities to retrieve from a repository.
+type PageRequest struct {
+	//Limit is the maximum number of entities in the page.
+	Limit int
+
+	//Cursor is the opaque value of a previous Page's Next field.
+	//The empty Cursor requests the first page.
+	Cursor string
+}
+
+//Validate returns an error if p cannot be used to retrieve a page.
+func (p PageRequest) Validate() error {
+	if p.Limit < 1 || p.Limit > MaxPageLimit {
+		return ErrInvalidPageLimit
+	}
+	_, err := DecodeCursor(p.Cursor)
+	return err
+}
+
+//Page describes a page of entities retrieved from a repository.
+type Page struct {
+	//Next is the Cursor to use to retrieve the next page.
+	//It is empty if there are no more pages.
+	Next string
+
+	//Total is the total number of entities in all pages.
+	Total int
+}
+
+//EncodeCursor returns an opaque cursor for key, the sort key of the last entity
+//in a page.
+func EncodeCursor(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+//DecodeCursor returns the sort key encoded in cursor by EncodeCursor.
+//The empty cursor decodes to the empty key.
+//ErrInvalidCursor is returned if cursor could not be decoded.
+func DecodeCursor(cursor string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+	return string(key), nil
+}
+
+//Paginate returns how many of the n retrieved entities belong in the page for
+//p, along with the Page describing them.
+//
+//Repositories should retrieve up to p.Limit+1 entities so that Paginate can tell
+//if there is a next page.
+//Total is the total number of entities in all pages, and key should return
+//the sort key of the retrieved entity at index i.
+func (p PageRequest) Paginate(n, total int, key func(i int) string) (int, Page) {
+	page := Page{
+		Total: total,
+	}
+
+	if n > p.Limit {
+		n = p.Limit
+		page.Next = EncodeCursor(key(n - 1))
+	}
+
+	return n, page
+}
+
+//timeKeyLayout is the fixed width layout of the time portion of a TimeKey.
+//Being fixed width means keys compare in the same order as their times.
+const timeKeyLayout = "2006-01-02T15:04:05.000000Z"
+
+//timeKeySeparator separates the time and Id portions of a TimeKey.
+const timeKeySeparator = "/"
+
+//TimeKey returns a sort key for entities sorted by a time, with microsecond
+//precision, and then by their Id.
+//Keys compare as strings in the same order as their entities.
+func TimeKey(t time.Time, id Id) string {
+	return t.UTC().Format(timeKeyLayout) + timeKeySeparator + id.String()
+}
+
+//ParseTimeKey returns the time and Id encoded in key by TimeKey.
+//ErrInvalidCursor is returned if key was not created by TimeKey.
+func ParseTimeKey(key string) (time.Time, Id, error) {
+	parts := strings.SplitN(key, timeKeySeparator, 2)
+	if len(parts) != 2 {
+		return time.Time{}, EmptyId(), ErrInvalidCursor
+	}
+
+	t, err := time.Parse(timeKeyLayout, parts[0])
+	if err != nil {
+		return time.Time{}, EmptyId(), ErrInvalidCursor
+	}
+
+	id, err := ParseId(parts[1])
+	if err != nil {
+		return time.Time{}, EmptyId(), ErrInvalidCursor
+	}
+
+	return t, id, nil
+}
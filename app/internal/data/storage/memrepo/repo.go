@@ -0,0 +1,347 @@
+package memrepo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+var (
+	//ErrForeignKeyViolation is returned when linking Ids that do not exist in
+	//their Relation's tables.
+	ErrForeignKeyViolation = errors.New("memrepo: foreign key violation")
+
+	//ErrUnknownRelation is returned when working with a Relation that was never
+	//defined with DefineRelation.
+	ErrUnknownRelation = errors.New("memrepo: unknown relation")
+)
+
+//Table is a collection of rows keyed by their Id.
+//Rows should be stored as values, not pointers, so that they can be safely
+//copied between transactions.
+type Table map[data.Id]interface{}
+
+//Relation is a collection of links from a left Id to a set of right Ids.
+type Relation map[data.Id]map[data.Id]struct{}
+
+//relationDef describes which tables the left and right sides of a Relation
+//reference.
+type relationDef struct {
+	left  string
+	right string
+}
+
+//Repo is an in-memory storage helper that domain repositories use in place
+//of a sqlrepo.Repo.
+//
+//It provides tables, relations between tables that behave like foreign keys
+//with ON DELETE CASCADE, and transactions with copy-on-write semantics.
+//It is safe for use by multiple goroutines.
+//
+//Repo is a data.Transactor.
+//ReadWorkContext and TxWorkContext called with a context returned from Transact
+//operate inside of that context's transaction.
+type Repo struct {
+	mu sync.RWMutex
+
+	tables map[string]Table
+
+	relations map[string]Relation
+
+	defs map[string]relationDef
+}
+
+//New returns a new, empty Repo.
+func New() *Repo {
+	return &Repo{
+		tables:    map[string]Table{},
+		relations: map[string]Relation{},
+		defs:      map[string]relationDef{},
+	}
+}
+
+//DefineRelation registers a Relation name whose left Ids must exist in table
+//left and whose right Ids must exist in table right.
+//Deleting a row from either table removes all of its links.
+//
+//Calling DefineRelation more than once with the same name is a no-op.
+func (r *Repo) DefineRelation(name, left, right string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.defs[name]; ok {
+		return
+	}
+
+	r.defs[name] = relationDef{
+		left:  left,
+		right: right,
+	}
+	r.relations[name] = Relation{}
+}
+
+//txKey is the context key for the transaction carried by contexts returned
+//from Transact.
+//It is keyed by Repo so that separate Repos do not share transactions.
+type txKey struct {
+	repo *Repo
+}
+
+//txFrom returns the transaction carried in ctx by r.Transact, if any.
+func (r *Repo) txFrom(ctx context.Context) (*Tx, bool) {
+	tx, ok := ctx.Value(txKey{r}).(*Tx)
+	return tx, ok
+}
+
+//Transact is the data.Transactor implementation.
+//R is locked for writing until work returns.
+//
+//If ctx already carries a transaction from r, work's changes to it are undone
+//if work returns an error.
+func (r *Repo) Transact(ctx context.Context, work func(ctx context.Context) error) error {
+	if tx, ok := r.txFrom(ctx); ok {
+		return tx.savepoint(func() error {
+			return work(ctx)
+		})
+	}
+
+	return r.TxWorkContext(ctx, func(tx *Tx) error {
+		return work(context.WithValue(ctx, txKey{r}, tx))
+	})
+}
+
+//ReadWorkContext executes work with a read-only view of r.
+//Work must not modify r through the Tx.
+//If ctx carries a transaction from Transact, work views that transaction's data.
+func (r *Repo) ReadWorkContext(ctx context.Context, work func(*Tx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if tx, ok := r.txFrom(ctx); ok {
+		return work(tx)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return work(&Tx{
+		tables:    r.tables,
+		relations: r.relations,
+		defs:      r.defs,
+	})
+}
+
+//TxWorkContext executes work inside of a transaction.
+//Work operates on a copy of r's data which replaces r's data only if work
+//returns a nil error.
+//If ctx carries a transaction from Transact, work is executed inside of it instead.
+func (r *Repo) TxWorkContext(ctx context.Context, work func(*Tx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if tx, ok := r.txFrom(ctx); ok {
+		return work(tx)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx := &Tx{
+		tables:    copyTables(r.tables),
+		relations: copyRelations(r.relations),
+		defs:      r.defs,
+	}
+
+	if err := work(tx); err != nil {
+		return err
+	}
+
+	r.tables, r.relations = tx.tables, tx.relations
+
+	return nil
+}
+
+//Close is the io.Closer implementation.
+//It always returns nil and exists so a Repo can stand in for a database connection.
+func (r *Repo) Close() error {
+	return nil
+}
+
+//Tx is a view of a Repo's data used inside ReadWorkContext and TxWorkContext.
+type Tx struct {
+	tables map[string]Table
+
+	relations map[string]Relation
+
+	defs map[string]relationDef
+}
+
+//savepoint executes work and restores tx's data to what it was before work if
+//work returns an error.
+func (tx *Tx) savepoint(work func() error) error {
+	tables, relations := copyTables(tx.tables), copyRelations(tx.relations)
+
+	if err := work(); err != nil {
+		tx.tables, tx.relations = tables, relations
+		return err
+	}
+
+	return nil
+}
+
+//Get returns the row with id in table and whether or not it exists.
+func (tx *Tx) Get(table string, id data.Id) (interface{}, bool) {
+	row, ok := tx.tables[table][id]
+	return row, ok
+}
+
+//Rows returns all rows in table in no particular order.
+func (tx *Tx) Rows(table string) []interface{} {
+	t := tx.tables[table]
+
+	result := make([]interface{}, 0, len(t))
+	for _, row := range t {
+		result = append(result, row)
+	}
+
+	return result
+}
+
+//Put adds or replaces the row with id in table.
+func (tx *Tx) Put(table string, id data.Id, row interface{}) {
+	t, ok := tx.tables[table]
+	if !ok {
+		t = Table{}
+		tx.tables[table] = t
+	}
+	t[id] = row
+}
+
+//Delete removes the row with id from table along with all links to and from
+//id in Relations that reference table.
+//It returns whether or not the row existed.
+func (tx *Tx) Delete(table string, id data.Id) bool {
+	if _, ok := tx.tables[table][id]; !ok {
+		return false
+	}
+
+	delete(tx.tables[table], id)
+
+	for name, def := range tx.defs {
+		relation := tx.relations[name]
+		if def.left == table {
+			delete(relation, id)
+		}
+		if def.right == table {
+			for _, rights := range relation {
+				delete(rights, id)
+			}
+		}
+	}
+
+	return true
+}
+
+//Linked returns the right Ids linked to left in relation sorted in byte order.
+func (tx *Tx) Linked(relation string, left data.Id) []data.Id {
+	rights := tx.relations[relation][left]
+	if len(rights) == 0 {
+		return nil
+	}
+
+	result := make([]data.Id, 0, len(rights))
+	for right := range rights {
+		result = append(result, right)
+	}
+	SortIds(result)
+
+	return result
+}
+
+//LinkedTo returns the set of right Ids that are linked to at least one left Id
+//in relation.
+func (tx *Tx) LinkedTo(relation string) map[data.Id]bool {
+	result := map[data.Id]bool{}
+	for _, rights := range tx.relations[relation] {
+		for right := range rights {
+			result[right] = true
+		}
+	}
+	return result
+}
+
+//SetLinks replaces all right Ids linked to left in relation with rights.
+//
+//ErrForeignKeyViolation is returned if left or any of rights do not exist in
+//their respective tables.
+func (tx *Tx) SetLinks(relation string, left data.Id, rights []data.Id) error {
+	def, ok := tx.defs[relation]
+	if !ok {
+		return ErrUnknownRelation
+	}
+
+	if len(rights) == 0 {
+		delete(tx.relations[relation], left)
+		return nil
+	}
+
+	if _, ok := tx.Get(def.left, left); !ok {
+		return ErrForeignKeyViolation
+	}
+
+	set := make(map[data.Id]struct{}, len(rights))
+	for _, right := range rights {
+		if _, ok := tx.Get(def.right, right); !ok {
+			return ErrForeignKeyViolation
+		}
+		set[right] = struct{}{}
+	}
+
+	tx.relations[relation][left] = set
+
+	return nil
+}
+
+//SortIds sorts ids in place by their byte order.
+//This matches how a database orders UUID columns.
+func SortIds(ids []data.Id) {
+	sort.Slice(ids, func(i, j int) bool {
+		return bytes.Compare(ids[i][:], ids[j][:]) < 0
+	})
+}
+
+//copyTables returns a copy of tables where each Table is also copied.
+func copyTables(tables map[string]Table) map[string]Table {
+	result := make(map[string]Table, len(tables))
+	for name, t := range tables {
+		copied := make(Table, len(t))
+		for id, row := range t {
+			copied[id] = row
+		}
+		result[name] = copied
+	}
+	return result
+}
+
+//copyRelations returns a deep copy of relations.
+func copyRelations(relations map[string]Relation) map[string]Relation {
+	result := make(map[string]Relation, len(relations))
+	for name, relation := range relations {
+		copied := make(Relation, len(relation))
+		for left, rights := range relation {
+			copiedRights := make(map[data.Id]struct{}, len(rights))
+			for right := range rights {
+				copiedRights[right] = struct{}{}
+			}
+			copied[left] = copiedRights
+		}
+		result[name] = copied
+	}
+	return result
+}
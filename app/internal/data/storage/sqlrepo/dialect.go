@@ -1,6 +1,10 @@
 package sqlrepo
 
-import "regexp"
+import (
+	"regexp"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
 
 //Placeholder is the only placeholder recognized by this package.
 //It is transparently parsed in queries into the Dialect specific representation.
@@ -14,6 +18,26 @@ type Dialect interface {
 	//Placeholder should return the DBMS specific placeholder for index.
 	//Index is zero-based.
 	Placeholder(index int) string
+
+	//UniqueViolation should return whether or not err is a driver error caused
+	//by violating a unique constraint.
+	//If it is, the name of the offending column should also be returned if it is known.
+	UniqueViolation(err error) (column string, ok bool)
+}
+
+//TranslateError returns a *data.ConflictError for entity if d reports err as
+//a unique constraint violation.
+//Otherwise err is returned.
+func TranslateError(d Dialect, err error, entity string) error {
+	if err == nil {
+		return nil
+	}
+
+	column, ok := d.UniqueViolation(err)
+	if !ok {
+		return err
+	}
+	return data.NewConflictError(entity, column)
 }
 
 //Normalize returns query with every instance of Placeholder replaced by a call
@@ -0,0 +1,80 @@
+package sqlrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//NoRows returns notFound if err is sql.ErrNoRows.
+//Otherwise err is returned.
+func NoRows(err error, notFound error) error {
+	if err == sql.ErrNoRows {
+		return notFound
+	}
+	return err
+}
+
+//RequireRowsAffected returns notFound if result reports that no rows were affected.
+//Err is returned if it is not nil.
+func RequireRowsAffected(result sql.Result, err error, notFound error) error {
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+
+	return nil
+}
+
+//RequireVersionedUpdate is a helper for the result of an update that only matches
+//the row with id in table if it is at an expected version.
+//
+//If no rows were affected, notFound is returned if there is no row with id in
+//table, and mismatch is returned otherwise.
+//Err is returned if it is not nil.
+func RequireVersionedUpdate(ctx context.Context, q QueryerContext, result sql.Result, err error, table string, id data.Id, notFound, mismatch error) error {
+	if err := RequireRowsAffected(result, err, mismatch); err != mismatch {
+		return err
+	}
+
+	count := 0
+	err = q.QueryRowContext(
+		ctx,
+		fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE id = ?", table),
+		id,
+	).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return notFound
+	}
+
+	return mismatch
+}
+
+//DeleteVersioned deletes the row with id from table.
+//If version is not nil, the row is only deleted if its version column equals
+//*version so that a concurrent update is never deleted unseen.
+//
+//If no rows were deleted, notFound is returned if there is no row with id in
+//table, and mismatch is returned otherwise.
+func DeleteVersioned(ctx context.Context, qec QueryExecerContext, table string, id data.Id, version *int, notFound, mismatch error) error {
+	query, args := fmt.Sprintf("DELETE FROM %s WHERE id = ?", table), []interface{}{id}
+	if version != nil {
+		query += " AND version = ?"
+		args = append(args, *version)
+	}
+
+	result, err := qec.ExecContext(ctx, query, args...)
+	return RequireVersionedUpdate(ctx, qec, result, err, table, id, notFound, mismatch)
+}
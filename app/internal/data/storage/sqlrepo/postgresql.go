@@ -1,9 +1,21 @@
 package sqlrepo
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/lib/pq"
+)
 
 var _ Dialect = PostgresqlDialect{} //Ensure PostgresqlDialect{} is a Dialect.
 
+//postgresqlUniqueViolation is the Postgresql error code for unique_violation.
+const postgresqlUniqueViolation = "23505"
+
+//postgresqlKeyDetail matches the detail of a unique_violation error and captures
+//the offending column, e.g. `Key (email)=(a@example.com) already exists.`
+var postgresqlKeyDetail = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
 //PostgresqlDialect is a Dialect that understands the Postgresql DBMS.
 type PostgresqlDialect struct{}
 
@@ -11,3 +23,17 @@ type PostgresqlDialect struct{}
 func (p PostgresqlDialect) Placeholder(index int) string {
 	return fmt.Sprintf("$%d", index+1)
 }
+
+//UniqueViolation is the Dialect implementation.
+func (p PostgresqlDialect) UniqueViolation(err error) (string, bool) {
+	pqErr, ok := err.(*pq.Error)
+	if !ok || pqErr.Code != postgresqlUniqueViolation {
+		return "", false
+	}
+
+	match := postgresqlKeyDetail.FindStringSubmatch(pqErr.Detail)
+	if match == nil {
+		return "", true
+	}
+	return match[1], true
+}
@@ -3,9 +3,14 @@ package sqlrepo
 import (
 	"context"
 	"database/sql"
+	"fmt"
 )
 
 //Repo provides utility methods to help working with the SQL package.
+//
+//Repo is a data.Transactor.
+//Its query, exec, and transaction methods called with a context returned from
+//Transact operate inside of that context's transaction.
 type Repo struct {
 	db *sql.DB
 
@@ -20,21 +25,43 @@ func New(db *sql.DB, d Dialect) *Repo {
 	}
 }
 
+//txKey is the context key for the transaction carried by contexts returned
+//from Transact.
+//It is keyed by Repo so that separate Repos do not share transactions.
+type txKey struct {
+	repo *Repo
+}
+
+//txFrom returns the transaction carried in ctx by r.Transact, if any.
+func (r *Repo) txFrom(ctx context.Context) (*tx, bool) {
+	t, ok := ctx.Value(txKey{r}).(*tx)
+	return t, ok
+}
+
 //QueryContext is the QueryerContext implementation.
-//It calls QueryContext on r's underlying sql.DB.
+//It calls QueryContext on ctx's transaction or r's underlying sql.DB.
 func (r *Repo) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if t, ok := r.txFrom(ctx); ok {
+		return t.QueryContext(ctx, query, args...)
+	}
 	return r.db.QueryContext(ctx, Normalize(r.d, query), args...)
 }
 
 //QueryRowContext is the QueryerContext implementation.
-//It calls QueryRowContext on r's underlying sql.DB.
+//It calls QueryRowContext on ctx's transaction or r's underlying sql.DB.
 func (r *Repo) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if t, ok := r.txFrom(ctx); ok {
+		return t.QueryRowContext(ctx, query, args...)
+	}
 	return r.db.QueryRowContext(ctx, Normalize(r.d, query), args...)
 }
 
 //ExecContext is the QueryExecerContext implementation.
-//It calls ExecContext on r's underlying sql.DB.
+//It calls ExecContext on ctx's transaction or r's underlying sql.DB.
 func (r *Repo) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if t, ok := r.txFrom(ctx); ok {
+		return t.ExecContext(ctx, query, args...)
+	}
 	return r.db.ExecContext(ctx, Normalize(r.d, query), args...)
 }
 
@@ -48,12 +75,69 @@ func (r *Repo) BeginContext(ctx context.Context) (Tx, error) {
 
 //TxWorkContext executes work inside of a transaction with ctx with committing and
 //rollback handled for you.
+//If ctx carries a transaction from Transact, work is executed inside of it instead,
+//and committing and rollback are left to Transact.
 func (r *Repo) TxWorkContext(ctx context.Context, work func(QueryExecerContext) error) error {
+	if t, ok := r.txFrom(ctx); ok {
+		return work(t)
+	}
+
+	sqlTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	err = work(&tx{sqlTx: sqlTx, d: r.d})
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+	return sqlTx.Commit()
+}
+
+//readTxOptions are the options of transactions started by ReadWorkContext.
+//Repeatable read gives each transaction a single snapshot of the database in
+//DBMSs whose default isolation level takes a snapshot for every statement.
+var readTxOptions = &sql.TxOptions{
+	Isolation: sql.LevelRepeatableRead,
+	ReadOnly:  true,
+}
+
+//ReadWorkContext executes work inside of a read only transaction with ctx so
+//that all of work's queries see the same data.
+//If ctx carries a transaction from Transact, work is executed inside of it instead.
+func (r *Repo) ReadWorkContext(ctx context.Context, work func(QueryerContext) error) error {
+	if t, ok := r.txFrom(ctx); ok {
+		return work(t)
+	}
+
+	sqlTx, err := r.db.BeginTx(ctx, readTxOptions)
+	if err != nil {
+		return err
+	}
+	err = work(&tx{sqlTx: sqlTx, d: r.d})
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+	return sqlTx.Commit()
+}
+
+//Transact is the data.Transactor implementation.
+//
+//If ctx already carries a transaction from r, work is executed inside of a
+//savepoint of it.
+func (r *Repo) Transact(ctx context.Context, work func(ctx context.Context) error) error {
+	if t, ok := r.txFrom(ctx); ok {
+		return t.savepoint(ctx, func() error {
+			return work(ctx)
+		})
+	}
+
 	sqlTx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	err = work(&tx{sqlTx, r.d})
+	err = work(context.WithValue(ctx, txKey{r}, &tx{sqlTx: sqlTx, d: r.d}))
 	if err != nil {
 		sqlTx.Rollback()
 		return err
@@ -61,6 +145,12 @@ func (r *Repo) TxWorkContext(ctx context.Context, work func(QueryExecerContext)
 	return sqlTx.Commit()
 }
 
+//TranslateError returns err translated into a domain error for entity by r's Dialect.
+//See TranslateError.
+func (r *Repo) TranslateError(err error, entity string) error {
+	return TranslateError(r.d, err, entity)
+}
+
 //Scanner is a wrapper interface around sql.Rows and sql.Row.
 type Scanner interface {
 	//Scan is the method to get a row into data.
@@ -105,6 +195,32 @@ type tx struct {
 	sqlTx *sql.Tx
 
 	d Dialect
+
+	//savepoints is the number of savepoints created in sqlTx.
+	//It is used to give each savepoint a unique name.
+	savepoints int
+}
+
+//savepoint executes work inside of a new savepoint in t.
+//The savepoint is rolled back if work returns an error so that only work's
+//changes are undone and t can still be used and committed.
+func (t *tx) savepoint(ctx context.Context, work func() error) error {
+	t.savepoints++
+	name := fmt.Sprintf("sp_%d", t.savepoints)
+
+	if _, err := t.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	if err := work(); err != nil {
+		//work's error is more useful to callers than a failure to roll back,
+		//which will cause the rest of the transaction to fail anyway.
+		t.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+		return err
+	}
+
+	_, err := t.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
 }
 
 func (t *tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
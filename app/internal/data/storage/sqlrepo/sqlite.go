@@ -0,0 +1,51 @@
+package sqlrepo
+
+import (
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var _ Dialect = SqliteDialect{} //Ensure SqliteDialect{} is a Dialect.
+
+//SqliteDialect is a Dialect that understands the SQLite DBMS.
+//
+//Ids are stored in their canonical string form, and times are stored in
+//text columns, so UTCTime understands how to scan times from strings.
+type SqliteDialect struct{}
+
+//Placeholder is the Dialect implementation.
+func (s SqliteDialect) Placeholder(_ int) string {
+	return "?"
+}
+
+//UniqueViolation is the Dialect implementation.
+func (s SqliteDialect) UniqueViolation(err error) (string, bool) {
+	sqliteErr, ok := err.(sqlite3.Error)
+	if !ok {
+		return "", false
+	}
+	if sqliteErr.ExtendedCode != sqlite3.ErrConstraintUnique && sqliteErr.ExtendedCode != sqlite3.ErrConstraintPrimaryKey {
+		return "", false
+	}
+
+	//The message looks like "UNIQUE constraint failed: users.email".
+	message := sqliteErr.Error()
+	column := message[strings.LastIndex(message, ".")+1:]
+	if strings.ContainsAny(column, " :") {
+		return "", true
+	}
+	return column, true
+}
+
+//SqliteDSN returns the data source name to open path with the SQLite driver.
+//
+//Foreign key enforcement is off by default in SQLite, so the returned data source
+//name turns it on for every connection.
+func SqliteDSN(path string) string {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + "_foreign_keys=1"
+}
@@ -0,0 +1,91 @@
+package sqlrepotest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/sqlrepo"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+//SqliteDatasourceName is the database/sql driver name of SQLite.
+const SqliteDatasourceName = "sqlite3"
+
+//changeLog is the root element of the schema changelog.
+type changeLog struct {
+	Imports []struct {
+		Path string `xml:"path,attr"`
+	} `xml:"Import"`
+}
+
+//changeSet is the root element of a file imported by the schema changelog.
+type changeSet struct {
+	Up []string `xml:"RawSql>Up>Stmt"`
+}
+
+//Open returns a new sqlrepo.Repo connected to a new, in memory SQLite database
+//that has had every change in the app's schema applied to it.
+//
+//The database is closed when t finishes.
+func Open(t *testing.T) *sqlrepo.Repo {
+	t.Helper()
+
+	db, err := sql.Open(SqliteDatasourceName, sqlrepo.SqliteDSN(":memory:"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	//Every connection to ":memory:" is its own database.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := sqlrepo.New(db, sqlrepo.SqliteDialect{})
+
+	for _, stmt := range schemaStmts(t) {
+		if _, err := repo.ExecContext(context.Background(), stmt); err != nil {
+			t.Fatalf("sqlrepotest: applying schema: %v\n%s", err, stmt)
+		}
+	}
+
+	return repo
+}
+
+//schemaStmts returns the Up statements of the app's schema in changelog order.
+func schemaStmts(t *testing.T) []string {
+	t.Helper()
+
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("sqlrepotest: cannot locate the schema directory")
+	}
+	dir := filepath.Join(filepath.Dir(file), "..", "..", "..", "..", "..", "schema")
+
+	log := changeLog{}
+	readXML(t, filepath.Join(dir, "changelog.xml"), &log)
+
+	stmts := []string{}
+	for _, i := range log.Imports {
+		set := changeSet{}
+		readXML(t, filepath.Join(dir, i.Path), &set)
+		stmts = append(stmts, set.Up...)
+	}
+	return stmts
+}
+
+//readXML decodes the XML file at path into v.
+func readXML(t *testing.T, path string, v interface{}) {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := xml.Unmarshal(b, v); err != nil {
+		t.Fatalf("sqlrepotest: %s: %v", path, err)
+	}
+}
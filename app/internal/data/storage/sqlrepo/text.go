@@ -14,3 +14,13 @@ func List(value string, count int) string {
 
 	return result
 }
+
+//Where returns a WHERE clause, with a leading space, that joins conditions
+//with AND.
+//No conditions results in the empty string.
+func Where(conditions ...string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
@@ -12,12 +12,39 @@ type UTCTime struct {
 	Time time.Time
 }
 
+//timeLayouts are the layouts, in order of preference, used to parse times that
+//are stored as text.
+//Layouts without a time zone are parsed as UTC.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	time.RFC3339Nano,
+}
+
 //Scan attempts to scan src into t.Time.
+//Src may be a time.Time or a string or []byte in one of the layouts used to
+//store times as text.
 //If scanning is successful, t.Time will be in UTC Timezone via time.Time.UTC().
 func (t *UTCTime) Scan(src interface{}) error {
-	srcTime, ok := src.(time.Time)
-	if !ok {
-		return fmt.Errorf("sqlrepo: invalid src type %T for scanning into UTCTime", src)
+	var srcTime time.Time
+	var err error
+
+	switch src := src.(type) {
+	case time.Time:
+		srcTime = src
+	case string:
+		srcTime, err = parseTime(src)
+	case []byte:
+		srcTime, err = parseTime(string(src))
+	default:
+		err = fmt.Errorf("sqlrepo: invalid src type %T for scanning into UTCTime", src)
+	}
+	if err != nil {
+		return err
 	}
 
 	t.Time = srcTime.UTC()
@@ -25,7 +52,51 @@ func (t *UTCTime) Scan(src interface{}) error {
 	return nil
 }
 
+//parseTime parses s with the first layout in timeLayouts that understands it.
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("sqlrepo: invalid time %q for scanning into UTCTime", s)
+}
+
 //Value returns t.Time.In(time.UTC), nil.
 func (t UTCTime) Value() (driver.Value, error) {
 	return t.Time.In(time.UTC), nil
 }
+
+//NullUTCTime is a UTCTime that may be NULL.
+//Valid is false if the scanned value was NULL.
+type NullUTCTime struct {
+	Time time.Time
+
+	Valid bool
+}
+
+//Scan attempts to scan src into t.Time as UTCTime does.
+//A nil src sets t.Valid to false.
+func (t *NullUTCTime) Scan(src interface{}) error {
+	if src == nil {
+		t.Time, t.Valid = time.Time{}, false
+		return nil
+	}
+
+	utc := UTCTime{}
+	if err := utc.Scan(src); err != nil {
+		return err
+	}
+
+	t.Time, t.Valid = utc.Time, true
+
+	return nil
+}
+
+//Value returns nil if t is not Valid and t.Time.In(time.UTC) otherwise.
+func (t NullUTCTime) Value() (driver.Value, error) {
+	if !t.Valid {
+		return nil, nil
+	}
+	return UTCTime{t.Time}.Value()
+}
@@ -0,0 +1,17 @@
+package data
+
+import "context"
+
+//Transactor executes work inside of a single storage transaction.
+type Transactor interface {
+	//Transact should call work with a context that carries a new transaction.
+	//Repositories backed by the same storage should perform all of their
+	//operations with that context inside the transaction.
+	//
+	//The transaction should be committed if work returns nil and rolled back
+	//otherwise, in which case work's error should be returned.
+	//If ctx already carries a transaction from the Transactor, work should join it
+	//as a nested transaction: if work returns an error only its changes should be
+	//rolled back, and the outer transaction should still be usable.
+	Transact(ctx context.Context, work func(ctx context.Context) error) error
+}
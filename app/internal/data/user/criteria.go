@@ -0,0 +1,44 @@
+package user
+
+import (
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//Criteria restricts which Users are listed.
+//Each non-nil field must be satisfied by a User for it to be listed.
+//The zero value matches all Users.
+type Criteria struct {
+	//Enabled, if not nil, must equal the User's Enabled field.
+	Enabled *bool
+
+	//ClientId, if not nil, must be the ClientId of one of the User's Memberships.
+	ClientId *data.Id
+
+	//CreatedAfter, if not nil, must be before the User's CreatedAt field.
+	CreatedAfter *time.Time
+}
+
+//Matches returns whether or not u satisfies c.
+func (c Criteria) Matches(u *User) bool {
+	if c.Enabled != nil && u.Enabled != *c.Enabled {
+		return false
+	}
+
+	if c.ClientId != nil && !hasClientId(u, *c.ClientId) {
+		return false
+	}
+
+	if c.CreatedAfter != nil && !u.CreatedAt.After(*c.CreatedAfter) {
+		return false
+	}
+
+	return true
+}
+
+//hasClientId returns whether or not u has a Membership for clientId.
+func hasClientId(u *User, clientId data.Id) bool {
+	_, ok := u.Membership(clientId)
+	return ok
+}
@@ -0,0 +1,52 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//ErrUnknownClientIds is a sentinel error indicating that a User references
+//Clients that do not exist.
+//Command handlers should prefer returning an *UnknownClientIdsError that lists
+//the missing ids.
+var ErrUnknownClientIds = errors.New("user: unknown client ids")
+
+//ErrInvalidRole is a sentinel error indicating that a Membership's Role is not
+//one of the known Roles.
+var ErrInvalidRole = errors.New("user: invalid role")
+
+//UnknownClientIdsError is an error indicating that a User could not be stored
+//because some of its client ids are not the Ids of existing Clients.
+type UnknownClientIdsError struct {
+	//ClientIds is the client ids that do not exist.
+	ClientIds []data.Id
+}
+
+//NewUnknownClientIdsError returns a new *UnknownClientIdsError for clientIds.
+func NewUnknownClientIdsError(clientIds []data.Id) *UnknownClientIdsError {
+	return &UnknownClientIdsError{
+		ClientIds: clientIds,
+	}
+}
+
+//Error is the error implementation.
+func (e *UnknownClientIdsError) Error() string {
+	ids := make([]string, len(e.ClientIds))
+	for i, id := range e.ClientIds {
+		ids[i] = id.String()
+	}
+	return fmt.Sprintf("user: unknown client ids %s", strings.Join(ids, ", "))
+}
+
+//IsUnknownClientIds returns whether or not err is ErrUnknownClientIds or an
+//*UnknownClientIdsError.
+func IsUnknownClientIds(err error) bool {
+	if err == ErrUnknownClientIds {
+		return true
+	}
+	_, ok := err.(*UnknownClientIdsError)
+	return ok
+}
@@ -0,0 +1,97 @@
+package user
+
+import "github.com/AgencyPMG/go-from-scratch/app/internal/data"
+
+//Names of the events emitted for Users.
+const (
+	EventCreated        = "user.created"
+	EventUpdated        = "user.updated"
+	EventDeleted        = "user.deleted"
+	EventClientsChanged = "user.clients_changed"
+)
+
+//UserCreated is the event emitted after a User is created.
+type UserCreated struct {
+	//User is the created User.
+	User User
+}
+
+//EventName is the event.Event implementation.
+func (e UserCreated) EventName() string {
+	return EventCreated
+}
+
+//UserUpdated is the event emitted after a User is updated.
+type UserUpdated struct {
+	//User is the User after it was updated.
+	User User
+}
+
+//EventName is the event.Event implementation.
+func (e UserUpdated) EventName() string {
+	return EventUpdated
+}
+
+//UserDeleted is the event emitted after a User is deleted.
+type UserDeleted struct {
+	//UserId is the Id of the deleted User.
+	UserId data.Id
+
+	//ClientIds is the client ids the User had when it was deleted.
+	ClientIds []data.Id
+}
+
+//EventName is the event.Event implementation.
+func (e UserDeleted) EventName() string {
+	return EventDeleted
+}
+
+//UserClientsChanged is the event emitted after the set of Clients a User
+//belongs to changes, including when a User is created with Clients.
+type UserClientsChanged struct {
+	//UserId is the Id of the User whose Clients changed.
+	UserId data.Id
+
+	//ClientIds is the User's new set of client ids.
+	ClientIds []data.Id
+
+	//Added is the client ids that the User did not have before the change.
+	Added []data.Id
+
+	//Removed is the client ids that the User no longer has after the change.
+	Removed []data.Id
+}
+
+//EventName is the event.Event implementation.
+func (e UserClientsChanged) EventName() string {
+	return EventClientsChanged
+}
+
+//NewUserClientsChanged returns the UserClientsChanged event for userId going
+//from the client ids before to the client ids after, and whether or not there
+//was a change at all.
+func NewUserClientsChanged(userId data.Id, before, after []data.Id) (UserClientsChanged, bool) {
+	e := UserClientsChanged{
+		UserId:    userId,
+		ClientIds: after,
+		Added:     missingIds(after, before),
+		Removed:   missingIds(before, after),
+	}
+	return e, len(e.Added) > 0 || len(e.Removed) > 0
+}
+
+//missingIds returns the ids in ids that are not in other.
+func missingIds(ids, other []data.Id) []data.Id {
+	set := make(map[data.Id]bool, len(other))
+	for _, id := range other {
+		set[id] = true
+	}
+
+	var result []data.Id
+	for _, id := range ids {
+		if !set[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
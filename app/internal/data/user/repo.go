@@ -2,6 +2,7 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
 )
@@ -10,19 +11,35 @@ import (
 type QueryRepo interface {
 	//Get should return the User whose Id equals id.
 	//
-	//An error should be returned if the User does not exist or there was an error
-	//attempting to load the User.
+	//A *data.NotFoundError should be returned if the User does not exist.
+	//Another error should be returned if there was an error attempting to load the User.
 	Get(ctx context.Context, id data.Id) (*User, error)
 
 	//GetEmail should return the User whose Email equals email.
 	//
-	//An error should be returned if the User does not exist or there was an error
-	//attempting to load the User.
+	//A *data.NotFoundError should be returned if the User does not exist.
+	//Another error should be returned if there was an error attempting to load the User.
 	GetEmail(ctx context.Context, email string) (*User, error)
 
 	//List should return all Users in the application.
 	//They should be sorted by their lexicographic Email order.
 	List(ctx context.Context) ([]*User, error)
+
+	//ListPage should return the page of Users that match criteria described by
+	//page in the same order as List.
+	//The Page's Total is the number of Users that match criteria.
+	//The Page's Next cursor is keyed on the Email of the last User.
+	//
+	//data.ErrInvalidCursor should be returned if page.Cursor is invalid.
+	ListPage(ctx context.Context, criteria Criteria, page data.PageRequest) ([]*User, data.Page, error)
+
+	//ListByClient should return the page of Users that belong to the Client with
+	//clientId described by page in the same order as List.
+	//The Page's Total is the number of Users that belong to the Client.
+	//The Page's Next cursor is keyed on the Email of the last User.
+	//
+	//data.ErrInvalidCursor should be returned if page.Cursor is invalid.
+	ListByClient(ctx context.Context, clientId data.Id, page data.PageRequest) ([]*User, data.Page, error)
 }
 
 //Repo provides method for creating and updating Users
@@ -32,9 +49,50 @@ type Repo interface {
 	QueryRepo
 
 	//Add should add u to the underlying storage repository.
+	//A *data.ConflictError should be returned if a unique field of u is already taken.
 	Add(ctx context.Context, u User) error
 
 	//Set should update all stored fields of u in the underlying storage repository.
 	//The update should use u.Id for determining which entity to update.
+	//
+	//The update should only happen if the stored version equals u.Version, and it
+	//should increment the stored version by one.
+	//A *data.VersionMismatchError should be returned if the stored version differs,
+	//and a *data.NotFoundError should be returned if the User does not exist.
+	//A *data.ConflictError should be returned if a unique field of u is already taken.
 	Set(ctx context.Context, u User) error
+
+	//Remove should remove the User with id, and all of its Client relationships,
+	//from the underlying storage repository.
+	//If version is not nil, the User should only be removed if its stored version
+	//equals *version, in the same statement that removes it.
+	//A *data.NotFoundError should be returned if the User does not exist, and a
+	//*data.VersionMismatchError should be returned if its version differs.
+	Remove(ctx context.Context, id data.Id, version *int) error
+
+	//AddClient should add m to the Memberships of the User with userId, or replace
+	//the User's Membership for m's ClientId, without replacing its other Memberships.
+	//If the User did not already have m, its UpdatedAt should be set to updatedAt
+	//and its stored version incremented by one.
+	//
+	//If version is not nil, nothing should change unless the User's stored version
+	//equals *version, in the same statement that increments it.
+	//
+	//The User as stored after the change is returned with whether or not it changed.
+	//A *data.NotFoundError should be returned if the User does not exist, and a
+	//*data.VersionMismatchError should be returned if its version differs.
+	AddClient(ctx context.Context, userId data.Id, m Membership, version *int, updatedAt time.Time) (*User, bool, error)
+
+	//RemoveClient should remove the Membership for clientId from the User with
+	//userId without replacing its other Memberships.
+	//If the User had one, its UpdatedAt should be set to updatedAt and its
+	//stored version incremented by one.
+	//
+	//If version is not nil, nothing should change unless the User's stored version
+	//equals *version, in the same statement that increments it.
+	//
+	//The User as stored after the change is returned with whether or not it changed.
+	//A *data.NotFoundError should be returned if the User does not exist, and a
+	//*data.VersionMismatchError should be returned if its version differs.
+	RemoveClient(ctx context.Context, userId, clientId data.Id, version *int, updatedAt time.Time) (*User, bool, error)
 }
@@ -1,11 +1,16 @@
 package user
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
 )
 
+//EntityName is the name used to refer to User entities in errors and other
+//descriptions.
+const EntityName = "user"
+
 //User is a domain type that represents a user of the application.
 type User struct {
 	//Id is the User's id.
@@ -25,7 +30,114 @@ type User struct {
 	//and is allowed to use the application.
 	Enabled bool
 
-	//ClientIds is a slice of Ids that represent all of the Clients that this User
-	//has access to.
+	//Memberships represent all of the Clients that this User has access to and
+	//the User's Role on each of them.
+	//A User has at most one Membership per Client.
+	Memberships []Membership
+
+	//Version is incremented every time this User is updated.
+	//New Users start at version 1.
+	Version int
+}
+
+//ClientIds returns the Ids of the Clients that u has access to in the order of
+//u.Memberships.
+func (u User) ClientIds() []data.Id {
+	if u.Memberships == nil {
+		return nil
+	}
+
+	result := make([]data.Id, len(u.Memberships))
+	for i, m := range u.Memberships {
+		result[i] = m.ClientId
+	}
+	return result
+}
+
+//MarshalJSON is the json.Marshaler implementation.
+//The ClientIds of u are included alongside its Memberships so that stored and
+//delivered payloads keep the ClientIds field Users were serialized with before
+//they had Memberships.
+func (u User) MarshalJSON() ([]byte, error) {
+	return json.Marshal(userJSON{
+		jsonUser:  jsonUser(u),
+		ClientIds: u.ClientIds(),
+	})
+}
+
+//UnmarshalJSON is the json.Unmarshaler implementation.
+//Payloads with ClientIds but no Memberships, serialized before Users had
+//Memberships, decode into Memberships with the DefaultRole.
+func (u *User) UnmarshalJSON(b []byte) error {
+	j := userJSON{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+
+	*u = User(j.jsonUser)
+	if u.Memberships == nil && j.ClientIds != nil {
+		u.Memberships = make([]Membership, len(j.ClientIds))
+		for i, clientId := range j.ClientIds {
+			u.Memberships[i] = Membership{ClientId: clientId, Role: DefaultRole}
+		}
+	}
+
+	return nil
+}
+
+//jsonUser is a User without its json methods.
+type jsonUser User
+
+//userJSON is the JSON representation of a User.
+type userJSON struct {
+	jsonUser
+
 	ClientIds []data.Id
 }
+
+//Membership returns u's Membership for the Client with clientId and whether or
+//not u has one.
+func (u User) Membership(clientId data.Id) (Membership, bool) {
+	for _, m := range u.Memberships {
+		if m.ClientId.Equal(clientId) {
+			return m, true
+		}
+	}
+	return Membership{}, false
+}
+
+//Role is the level of access a User has to a Client.
+type Role string
+
+//Roles a User may have on a Client.
+const (
+	//RoleAdmin can manage the Client and who has access to it.
+	RoleAdmin Role = "admin"
+
+	//RoleEditor can change the Client's data.
+	RoleEditor Role = "editor"
+
+	//RoleViewer can only view the Client's data.
+	RoleViewer Role = "viewer"
+)
+
+//DefaultRole is the Role of Memberships that are created without one.
+const DefaultRole = RoleViewer
+
+//Valid returns whether or not r is one of the known Roles.
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleEditor, RoleViewer:
+		return true
+	}
+	return false
+}
+
+//Membership is a User's access to a single Client.
+type Membership struct {
+	//ClientId is the Id of the Client the User has access to.
+	ClientId data.Id
+
+	//Role is the User's Role on the Client.
+	Role Role
+}
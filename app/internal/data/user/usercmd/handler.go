@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
 	"github.com/gogolfing/cbus"
 )
@@ -14,22 +16,47 @@ import (
 type Handler struct {
 	//Users is the repo to use to manager Users.
 	Users user.Repo
+
+	//Clients is used to make sure the client ids of Users exist.
+	Clients client.QueryRepo
+
+	//Events is where events are published after Users are successfully changed.
+	Events event.Publisher
 }
 
 //CreateUser attempts to create a new User and add it to h.Users.
 //Cmd must be of type *CreateUserCommand.
 //The result, if not nil and without error, is a *user.User.
+//A *user.UnknownClientIdsError is returned if any of the command's Memberships'
+//Clients do not exist, and user.ErrInvalidRole is returned if any of their
+//Roles are invalid.
+//
+//A user.UserCreated event is published, followed by a user.UserClientsChanged
+//event if the User has Memberships.
 func (h *Handler) CreateUser(ctx context.Context, cmd cbus.Command) (interface{}, error) {
 	createUser := cmd.(*CreateUserCommand)
 
-	user, err := createUser.newUser()
+	memberships, err := h.requireMemberships(ctx, nil, createUser.Memberships)
 	if err != nil {
 		return nil, err
 	}
+	createUser.Memberships = memberships
 
-	err = h.Users.Add(ctx, *user)
+	u, err := createUser.newUser()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.Users.Add(ctx, *u); err != nil {
+		return u, err
+	}
 
-	return user, err
+	events := []event.Event{user.UserCreated{User: *u}}
+	if changed, ok := user.NewUserClientsChanged(u.Id, nil, u.ClientIds()); ok {
+		events = append(events, changed)
+	}
+
+	return u, h.Events.Publish(ctx, events...)
 }
 
 //CreateUserCommand is a Command that should be used to create a new User.
@@ -40,8 +67,9 @@ type CreateUserCommand struct {
 	//Enabled is the new User's Enabeld field.
 	Enabled bool
 
-	//ClientIds is the new User's ClientIds field.
-	ClientIds []data.Id
+	//Memberships is the new User's Memberships field.
+	//Memberships without a Role get user.DefaultRole.
+	Memberships []user.Membership
 }
 
 func (c *CreateUserCommand) newUser() (*user.User, error) {
@@ -53,31 +81,150 @@ func (c *CreateUserCommand) newUser() (*user.User, error) {
 	now := time.Now()
 
 	return &user.User{
-		Id:        id,
-		Email:     c.Email,
-		CreatedAt: now,
-		UpdatedAt: now,
-		Enabled:   c.Enabled,
-		ClientIds: c.ClientIds,
+		Id:          id,
+		Email:       c.Email,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Enabled:     c.Enabled,
+		Memberships: c.Memberships,
+		Version:     1,
 	}, nil
 }
 
 //UpdateUser attempts to update a User from cmd.
 //Cmd must be of type *UpdateUserCommand.
 //The result, if not nil and without error, is a *user.User.
+//A *data.NotFoundError is returned if the User does not exist.
+//A *data.VersionMismatchError is returned if the User is not at the command's
+//Version or if it is changed concurrently.
+//A *user.UnknownClientIdsError is returned if any of the command's Memberships'
+//Clients do not exist, and user.ErrInvalidRole is returned if any of their
+//Roles are invalid.
+//
+//A user.UserUpdated event is published, followed by a user.UserClientsChanged
+//event if the User's client ids changed.
 func (h *Handler) UpdateUser(ctx context.Context, cmd cbus.Command) (interface{}, error) {
 	updateUser := cmd.(*UpdateUserCommand)
 
-	user, err := h.Users.Get(ctx, updateUser.Id)
+	u, err := h.getVersion(ctx, updateUser.Id, updateUser.Version)
 	if err != nil {
 		return nil, err
 	}
 
-	updateUser.updateUser(user)
+	if updateUser.Memberships != nil {
+		memberships, err := h.requireMemberships(ctx, u, *updateUser.Memberships)
+		if err != nil {
+			return nil, err
+		}
+		updateUser.Memberships = &memberships
+	}
+	clientIds := u.ClientIds()
+
+	updateUser.updateUser(u)
+
+	if err := h.Users.Set(ctx, *u); err != nil {
+		return nil, err
+	}
+	u.Version++
+
+	events := []event.Event{user.UserUpdated{User: *u}}
+	if changed, ok := user.NewUserClientsChanged(u.Id, clientIds, u.ClientIds()); ok {
+		events = append(events, changed)
+	}
+
+	return u, h.Events.Publish(ctx, events...)
+}
+
+//requireMemberships is a helper method to return memberships with at most one
+//Membership per Client, where later Memberships replace earlier ones.
+//Empty Roles are replaced by the Role current, if not nil, already has for the
+//Client, or user.DefaultRole otherwise.
+//
+//user.ErrInvalidRole is returned if any other Role is invalid, and the errors of
+//requireClients are returned if any of the Clients do not exist.
+func (h *Handler) requireMemberships(ctx context.Context, current *user.User, memberships []user.Membership) ([]user.Membership, error) {
+	if memberships == nil {
+		return nil, nil
+	}
+
+	result := make([]user.Membership, 0, len(memberships))
+	indexes := make(map[data.Id]int, len(memberships))
+
+	for _, m := range memberships {
+		if m.Role == "" {
+			m.Role = user.DefaultRole
+			if current != nil {
+				if existing, ok := current.Membership(m.ClientId); ok {
+					m.Role = existing.Role
+				}
+			}
+		}
+		if !m.Role.Valid() {
+			return nil, user.ErrInvalidRole
+		}
+
+		if i, ok := indexes[m.ClientId]; ok {
+			result[i] = m
+			continue
+		}
+		indexes[m.ClientId] = len(result)
+		result = append(result, m)
+	}
+
+	clientIds := user.User{Memberships: result}.ClientIds()
+	if err := h.requireClients(ctx, clientIds); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+//requireClients is a helper method to make sure a Client exists in h.Clients for
+//each of clientIds.
+//A *user.UnknownClientIdsError listing the missing ids is returned otherwise.
+func (h *Handler) requireClients(ctx context.Context, clientIds []data.Id) error {
+	if len(clientIds) == 0 {
+		return nil
+	}
+
+	clients, err := h.Clients.ListByIds(ctx, clientIds)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[data.Id]bool, len(clients))
+	for _, c := range clients {
+		found[c.Id] = true
+	}
+
+	var missing []data.Id
+	for _, id := range clientIds {
+		if !found[id] {
+			missing = append(missing, id)
+			//Only list each missing id once.
+			found[id] = true
+		}
+	}
+
+	if len(missing) > 0 {
+		return user.NewUnknownClientIdsError(missing)
+	}
+	return nil
+}
 
-	err = h.Users.Set(ctx, *user)
+//getVersion is a helper method to get the User with id from h.Users and make
+//sure it is at version if version is not nil.
+func (h *Handler) getVersion(ctx context.Context, id data.Id, version *int) (*user.User, error) {
+	u, err := h.Users.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != nil && u.Version != *version {
+		return nil, data.NewVersionMismatchError(user.EntityName, id)
+	}
 
-	return user, err
+	return u, nil
 }
 
 //UpdateUserCommand is a Command that should be used to update single User
@@ -92,8 +239,13 @@ type UpdateUserCommand struct {
 	//Enabled, if not nil, is the enabled value to set on the User.
 	Enabled *bool
 
-	//ClientIds, if not nil, is the slice of client ids to set on the User.
-	ClientIds *[]data.Id
+	//Memberships, if not nil, is the slice of Memberships to set on the User.
+	//Memberships without a Role keep the User's current Role for their Client,
+	//or get user.DefaultRole if the User does not already have the Client.
+	Memberships *[]user.Membership
+
+	//Version, if not nil, is the Version the User must be at to be updated.
+	Version *int
 }
 
 func (c *UpdateUserCommand) updateUser(user *user.User) {
@@ -107,7 +259,180 @@ func (c *UpdateUserCommand) updateUser(user *user.User) {
 	if c.Enabled != nil {
 		user.Enabled = *c.Enabled
 	}
-	if c.ClientIds != nil {
-		user.ClientIds = *c.ClientIds
+	if c.Memberships != nil {
+		user.Memberships = *c.Memberships
+	}
+}
+
+//AddUserClient attempts to add a Client to the Clients of a User in h.Users
+//without replacing its other Clients.
+//Cmd must be of type *AddUserClientCommand.
+//The result, if not nil and without error, is the *user.User after the change.
+//A *data.NotFoundError is returned if the User or the Client does not exist.
+//A *data.VersionMismatchError is returned if the User is not at the command's Version.
+//
+//user.ErrInvalidRole is returned if the command's Role is invalid.
+//
+//Adding a Client the User already has with the same Role changes nothing.
+//Otherwise a user.UserUpdated event is published, followed by a
+//user.UserClientsChanged event if the User did not already have the Client.
+func (h *Handler) AddUserClient(ctx context.Context, cmd cbus.Command) (interface{}, error) {
+	addUserClient := cmd.(*AddUserClientCommand)
+
+	if addUserClient.Role != "" && !addUserClient.Role.Valid() {
+		return nil, user.ErrInvalidRole
 	}
+
+	u, err := h.requireUserClient(ctx, addUserClient.UserId, addUserClient.ClientId)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, isMember := u.Membership(addUserClient.ClientId)
+
+	m := user.Membership{
+		ClientId: addUserClient.ClientId,
+		Role:     addUserClient.Role,
+	}
+	if m.Role == "" {
+		m.Role = user.DefaultRole
+		if isMember {
+			m.Role = existing.Role
+		}
+	}
+
+	u, changed, err := h.Users.AddClient(ctx, addUserClient.UserId, m, addUserClient.Version, time.Now())
+	if err != nil || !changed {
+		return u, err
+	}
+
+	events := []event.Event{user.UserUpdated{User: *u}}
+	if !isMember {
+		events = append(events, user.UserClientsChanged{
+			UserId:    u.Id,
+			ClientIds: u.ClientIds(),
+			Added:     []data.Id{addUserClient.ClientId},
+		})
+	}
+
+	return u, h.Events.Publish(ctx, events...)
+}
+
+//AddUserClientCommand is a Command that should be used to add a single Client
+//to a User.
+type AddUserClientCommand struct {
+	//UserId is the Id of the User to add the Client to.
+	UserId data.Id
+
+	//ClientId is the Id of the Client to add.
+	ClientId data.Id
+
+	//Role is the Role of the User for the Client.
+	//If empty, the User keeps its current Role, or gets user.DefaultRole if it
+	//does not already have the Client.
+	Role user.Role
+
+	//Version, if not nil, is the Version the User must be at to be changed.
+	Version *int
+}
+
+//RemoveUserClient attempts to remove a Client from the Clients of a User in
+//h.Users without replacing its other Clients.
+//Cmd must be of type *RemoveUserClientCommand.
+//The result, if not nil and without error, is the *user.User after the change.
+//A *data.NotFoundError is returned if the User or the Client does not exist.
+//A *data.VersionMismatchError is returned if the User is not at the command's Version.
+//
+//Removing a Client the User does not have changes nothing.
+//Otherwise a user.UserUpdated event is published, followed by a
+//user.UserClientsChanged event.
+func (h *Handler) RemoveUserClient(ctx context.Context, cmd cbus.Command) (interface{}, error) {
+	removeUserClient := cmd.(*RemoveUserClientCommand)
+
+	if _, err := h.requireUserClient(ctx, removeUserClient.UserId, removeUserClient.ClientId); err != nil {
+		return nil, err
+	}
+
+	u, removed, err := h.Users.RemoveClient(ctx, removeUserClient.UserId, removeUserClient.ClientId, removeUserClient.Version, time.Now())
+	if err != nil || !removed {
+		return u, err
+	}
+
+	return u, h.Events.Publish(
+		ctx,
+		user.UserUpdated{User: *u},
+		user.UserClientsChanged{
+			UserId:    u.Id,
+			ClientIds: u.ClientIds(),
+			Removed:   []data.Id{removeUserClient.ClientId},
+		},
+	)
+}
+
+//RemoveUserClientCommand is a Command that should be used to remove a single
+//Client from a User.
+type RemoveUserClientCommand struct {
+	//UserId is the Id of the User to remove the Client from.
+	UserId data.Id
+
+	//ClientId is the Id of the Client to remove.
+	ClientId data.Id
+
+	//Version, if not nil, is the Version the User must be at to be changed.
+	Version *int
+}
+
+//requireUserClient is a helper method to make sure the User with userId and the
+//Client with clientId exist.
+//The User is returned if both exist.
+//
+//The User's version is checked by h.Users when the Membership is changed so that
+//concurrent changes with the same version cannot both succeed.
+func (h *Handler) requireUserClient(ctx context.Context, userId, clientId data.Id) (*user.User, error) {
+	u, err := h.Users.Get(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := h.Clients.Get(ctx, clientId); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+//DeleteUser attempts to remove a User from h.Users.
+//Cmd must be of type *DeleteUserCommand.
+//The result will always be nil.
+//A non-nil error will be returned to indicate failure.
+//A *data.NotFoundError is returned if the User does not exist.
+//A *data.VersionMismatchError is returned if the User is not at the command's Version.
+//
+//A user.UserDeleted event is published.
+func (h *Handler) DeleteUser(ctx context.Context, cmd cbus.Command) (interface{}, error) {
+	deleteUser := cmd.(*DeleteUserCommand)
+
+	//The User is retrieved for its event, and Remove checks its Version so that
+	//a concurrent update cannot be deleted unseen.
+	u, err := h.Users.Get(ctx, deleteUser.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.Users.Remove(ctx, deleteUser.Id, deleteUser.Version); err != nil {
+		return nil, err
+	}
+
+	return nil, h.Events.Publish(ctx, user.UserDeleted{
+		UserId:    u.Id,
+		ClientIds: u.ClientIds(),
+	})
+}
+
+//DeleteUserCommand is a Command to delete (remove) a User.
+type DeleteUserCommand struct {
+	//Id is the Id of the User to delete.
+	Id data.Id
+
+	//Version, if not nil, is the Version the User must be at to be deleted.
+	Version *int
 }
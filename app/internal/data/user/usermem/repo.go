@@ -0,0 +1,308 @@
+package usermem
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change/changemem"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/memrepo"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+)
+
+var _ user.Repo = &Repo{} //Ensure *Repo is a user.Repo.
+
+const (
+	//Table is the memrepo table we store our user entities in.
+	Table = "users"
+
+	//RelationUserClients is the memrepo relation for our user, client relationships.
+	RelationUserClients = "user_clients"
+
+	//TableClients is the memrepo table that client entities are stored in.
+	//It must match the table used by the client memory repository.
+	TableClients = "clients"
+)
+
+//Repo is a user.Repo implementation that stores Users in memory.
+type Repo struct {
+	mem *memrepo.Repo
+}
+
+//New returns a new Repo that uses repo as its storage.
+//It defines the RelationUserClients relation on repo.
+func New(repo *memrepo.Repo) *Repo {
+	repo.DefineRelation(RelationUserClients, Table, TableClients)
+
+	return &Repo{
+		mem: repo,
+	}
+}
+
+//Get is the user.QueryRepo implementation.
+func (r *Repo) Get(ctx context.Context, id data.Id) (*user.User, error) {
+	return r.get(ctx, "id", id, func(u *user.User) bool {
+		return u.Id.Equal(id)
+	})
+}
+
+//GetEmail is the user.QueryRepo implementation.
+func (r *Repo) GetEmail(ctx context.Context, email string) (*user.User, error) {
+	return r.get(ctx, "email", email, func(u *user.User) bool {
+		return u.Email == email
+	})
+}
+
+//get is a helper method to get the single User for which match returns true.
+//Field and value describe match in the error returned if no User matches.
+func (r *Repo) get(ctx context.Context, field string, value interface{}, match func(*user.User) bool) (*user.User, error) {
+	users, err := r.list(ctx, match)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, data.NewNotFoundError(user.EntityName, field, value)
+	}
+
+	return users[0], nil
+}
+
+//List is the user.QueryRepo implementation.
+func (r *Repo) List(ctx context.Context) ([]*user.User, error) {
+	return r.list(ctx, func(*user.User) bool {
+		return true
+	})
+}
+
+//ListPage is the user.QueryRepo implementation.
+func (r *Repo) ListPage(ctx context.Context, criteria user.Criteria, page data.PageRequest) ([]*user.User, data.Page, error) {
+	after, err := data.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	users, err := r.list(ctx, criteria.Matches)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	total := len(users)
+	start := sort.Search(total, func(i int) bool {
+		return users[i].Email > after
+	})
+	users = users[start:]
+	if len(users) > page.Limit+1 {
+		users = users[:page.Limit+1]
+	}
+
+	n, result := page.Paginate(len(users), total, func(i int) string {
+		return users[i].Email
+	})
+
+	return users[:n], result, nil
+}
+
+//ListByClient is the user.QueryRepo implementation.
+func (r *Repo) ListByClient(ctx context.Context, clientId data.Id, page data.PageRequest) ([]*user.User, data.Page, error) {
+	return r.ListPage(ctx, user.Criteria{ClientId: &clientId}, page)
+}
+
+//list is a helper method to return all Users, with their client ids populated,
+//for which include returns true in the order the application expects.
+func (r *Repo) list(ctx context.Context, include func(*user.User) bool) ([]*user.User, error) {
+	result := []*user.User{}
+
+	err := r.mem.ReadWorkContext(ctx, func(tx *memrepo.Tx) error {
+		for _, row := range tx.Rows(Table) {
+			u := fromRow(tx, row)
+			if include(u) {
+				result = append(result, u)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortUsers(result)
+
+	return result, nil
+}
+
+//sortUsers sorts users by email ascending.
+func sortUsers(users []*user.User) {
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Email < users[j].Email
+	})
+}
+
+//fromRow is a helper function to return a new User copied from a stored row
+//with its Memberships populated from tx.
+//
+//The links in RelationUserClients determine which Memberships the User has, since
+//they are removed when Clients are deleted, and the row's Memberships provide
+//their roles.
+func fromRow(tx *memrepo.Tx, row interface{}) *user.User {
+	u := row.(user.User)
+
+	roles := make(map[data.Id]user.Role, len(u.Memberships))
+	for _, m := range u.Memberships {
+		roles[m.ClientId] = m.Role
+	}
+
+	u.Memberships = nil
+	for _, clientId := range tx.Linked(RelationUserClients, u.Id) {
+		u.Memberships = append(u.Memberships, user.Membership{
+			ClientId: clientId,
+			Role:     roles[clientId],
+		})
+	}
+
+	return &u
+}
+
+//Add is the user.Repo implementation.
+func (r *Repo) Add(ctx context.Context, u user.User) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		if _, ok := tx.Get(Table, u.Id); ok {
+			return data.NewConflictError(user.EntityName, "id")
+		}
+		return put(tx, u)
+	})
+}
+
+//Set is the user.Repo implementation.
+func (r *Repo) Set(ctx context.Context, u user.User) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		row, ok := tx.Get(Table, u.Id)
+		if !ok {
+			return data.NewNotFoundError(user.EntityName, "id", u.Id)
+		}
+		if row.(user.User).Version != u.Version {
+			return data.NewVersionMismatchError(user.EntityName, u.Id)
+		}
+
+		u.Version++
+		return put(tx, u)
+	})
+}
+
+//Remove is the user.Repo implementation.
+//The User's client relationships are removed along with it.
+func (r *Repo) Remove(ctx context.Context, id data.Id, version *int) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		row, ok := tx.Get(Table, id)
+		if !ok {
+			return data.NewNotFoundError(user.EntityName, "id", id)
+		}
+		if version != nil && row.(user.User).Version != *version {
+			return data.NewVersionMismatchError(user.EntityName, id)
+		}
+
+		tx.Delete(Table, id)
+		return changemem.Append(tx, change.Delete(user.EntityName, id))
+	})
+}
+
+//AddClient is the user.Repo implementation.
+func (r *Repo) AddClient(ctx context.Context, userId data.Id, m user.Membership, version *int, updatedAt time.Time) (*user.User, bool, error) {
+	return r.changeMemberships(ctx, userId, version, updatedAt, func(memberships []user.Membership) ([]user.Membership, bool) {
+		result := []user.Membership{m}
+		for _, other := range memberships {
+			if other.ClientId.Equal(m.ClientId) {
+				if other.Role == m.Role {
+					return memberships, false
+				}
+				continue
+			}
+			result = append(result, other)
+		}
+		return result, true
+	})
+}
+
+//RemoveClient is the user.Repo implementation.
+func (r *Repo) RemoveClient(ctx context.Context, userId, clientId data.Id, version *int, updatedAt time.Time) (*user.User, bool, error) {
+	return r.changeMemberships(ctx, userId, version, updatedAt, func(memberships []user.Membership) ([]user.Membership, bool) {
+		result := []user.Membership{}
+		for _, m := range memberships {
+			if !m.ClientId.Equal(clientId) {
+				result = append(result, m)
+			}
+		}
+		return result, len(result) != len(memberships)
+	})
+}
+
+//changeMemberships is a helper method to replace the Memberships of the User with
+//userId with the result of update, and to update the User if update reports a change.
+//Nothing changes unless the User is at version if it is not nil.
+func (r *Repo) changeMemberships(ctx context.Context, userId data.Id, version *int, updatedAt time.Time, update func([]user.Membership) ([]user.Membership, bool)) (*user.User, bool, error) {
+	var u *user.User
+	changed := false
+
+	err := r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		row, ok := tx.Get(Table, userId)
+		if !ok {
+			return data.NewNotFoundError(user.EntityName, "id", userId)
+		}
+		u = fromRow(tx, row)
+
+		if version != nil && u.Version != *version {
+			return data.NewVersionMismatchError(user.EntityName, userId)
+		}
+
+		var memberships []user.Membership
+		if memberships, changed = update(u.Memberships); !changed {
+			return nil
+		}
+
+		u.Memberships = memberships
+		u.UpdatedAt = updatedAt
+		u.Version++
+
+		if err := put(tx, *u); err != nil {
+			return err
+		}
+
+		//Read u back so its Memberships are in the same order as other reads.
+		row, _ = tx.Get(Table, userId)
+		u = fromRow(tx, row)
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return u, changed, nil
+}
+
+//put is a helper function to store u, do a full update of its Memberships, and
+//record it in the change feed.
+func put(tx *memrepo.Tx, u user.User) error {
+	for _, row := range tx.Rows(Table) {
+		other := row.(user.User)
+		if other.Email == u.Email && !other.Id.Equal(u.Id) {
+			return data.NewConflictError(user.EntityName, "email")
+		}
+	}
+
+	c, err := change.Upsert(user.EntityName, u.Id, u)
+	if err != nil {
+		return err
+	}
+
+	//The row keeps a copy of the Memberships for their roles.
+	clientIds := u.ClientIds()
+	u.Memberships = append([]user.Membership(nil), u.Memberships...)
+
+	tx.Put(Table, u.Id, u)
+
+	if err := tx.SetLinks(RelationUserClients, u.Id, clientIds); err != nil {
+		return err
+	}
+	return changemem.Append(tx, c)
+}
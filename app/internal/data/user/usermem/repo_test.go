@@ -0,0 +1,19 @@
+package usermem_test
+
+import (
+	"testing"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client/clientmem"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/memrepo"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user/usermem"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user/usertest"
+)
+
+func TestRepo(t *testing.T) {
+	usertest.TestRepo(t, func(t *testing.T) (user.Repo, client.Repo) {
+		mem := memrepo.New()
+		return usermem.New(mem), clientmem.New(mem)
+	})
+}
@@ -3,8 +3,11 @@ package usersql
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change/changesql"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/sqlrepo"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
 )
@@ -25,11 +28,15 @@ const SelectFrom = `SELECT
 	u.email,
 	u.created_at,
 	u.updated_at,
-	u.enabled
+	u.enabled,
+	u.version
 	FROM ` + Table + ` AS u`
 
+//CountFrom is our count query without filtering.
+const CountFrom = `SELECT COUNT(*) FROM ` + Table + ` AS u`
+
 //SelectFromUserClients is our select query for user, client relationships.
-const SelectFromUserClients = `SELECT user_id, client_id FROM ` + TableUserClients
+const SelectFromUserClients = `SELECT user_id, client_id, role FROM ` + TableUserClients
 
 //Repo is a user.Repo implementation that uses a SQL database as its storage.
 type Repo struct {
@@ -45,41 +52,144 @@ func New(repo *sqlrepo.Repo) *Repo {
 
 //Get is the user.QueryRepo implementation.
 func (r *Repo) Get(ctx context.Context, id data.Id) (*user.User, error) {
-	return r.get(
-		ctx,
-		fmt.Sprintf("%s WHERE id = ?", SelectFrom),
-		id,
-	)
+	return r.get(ctx, r.db, "id", id)
 }
 
 //GetEmail is the user.QueryRepo implementation.
 func (r *Repo) GetEmail(ctx context.Context, email string) (*user.User, error) {
-	return r.get(
-		ctx,
-		fmt.Sprintf("%s WHERE email = ?", SelectFrom),
-		email,
-	)
+	return r.get(ctx, r.db, "email", email)
 }
 
-//get is a helper method to get a user from an independent get query.
-func (r *Repo) get(ctx context.Context, query string, args ...interface{}) (*user.User, error) {
-	row := r.db.QueryRowContext(ctx, query, args...)
+//get is a helper method to get the user whose column field equals value with q.
+func (r *Repo) get(ctx context.Context, q sqlrepo.QueryerContext, field string, value interface{}) (*user.User, error) {
+	row := q.QueryRowContext(
+		ctx,
+		fmt.Sprintf("%s WHERE %s = ?", SelectFrom, field),
+		value,
+	)
 	u, err := scan(row)
 	if err != nil {
-		return nil, err
+		return nil, sqlrepo.NoRows(err, data.NewNotFoundError(user.EntityName, field, value))
 	}
 
-	err = r.populateClientIds(ctx, []*user.User{u})
+	err = r.populateMemberships(ctx, q, []*user.User{u})
 
 	return u, err
 }
 
 //List is the user.QueryRepo implementation.
 func (r *Repo) List(ctx context.Context) ([]*user.User, error) {
-	rows, err := r.db.QueryContext(
-		ctx,
-		fmt.Sprintf("%s ORDER BY email ASC", SelectFrom),
-	)
+	return r.list(ctx, r.db, orderQuery(SelectFrom))
+}
+
+//ListPage is the user.QueryRepo implementation.
+//The Users are counted and listed in a single read transaction so that the
+//Page describes them.
+func (r *Repo) ListPage(ctx context.Context, criteria user.Criteria, page data.PageRequest) ([]*user.User, data.Page, error) {
+	after, err := data.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	conditions, args := criteriaConditions(criteria)
+	count := CountFrom + sqlrepo.Where(conditions...)
+	countArgs := args
+
+	if after != "" {
+		conditions = append(conditions, "u.email > ?")
+		args = append(args, after)
+	}
+	query := orderQuery(SelectFrom+sqlrepo.Where(conditions...)) + " LIMIT ?"
+	args = append(args, page.Limit+1)
+
+	return r.listPage(ctx, page, count, countArgs, query, args)
+}
+
+//ListByClient is the user.QueryRepo implementation.
+//Users are joined to the Client's rows in TableUserClients.
+func (r *Repo) ListByClient(ctx context.Context, clientId data.Id, page data.PageRequest) ([]*user.User, data.Page, error) {
+	after, err := data.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	count := fmt.Sprintf("SELECT COUNT(*) FROM %s AS uc WHERE uc.client_id = ?", TableUserClients)
+	countArgs := []interface{}{clientId}
+
+	conditions, args := []string{"uc.client_id = ?"}, []interface{}{clientId}
+	if after != "" {
+		conditions = append(conditions, "u.email > ?")
+		args = append(args, after)
+	}
+	query := fmt.Sprintf("%s INNER JOIN %s AS uc ON uc.user_id = u.id", SelectFrom, TableUserClients)
+	query = orderQuery(query+sqlrepo.Where(conditions...)) + " LIMIT ?"
+	args = append(args, page.Limit+1)
+
+	return r.listPage(ctx, page, count, countArgs, query, args)
+}
+
+//listPage is a helper method to count the Users with count and list up to a
+//page of them with query, inside of a single read transaction.
+func (r *Repo) listPage(ctx context.Context, page data.PageRequest, count string, countArgs []interface{}, query string, args []interface{}) ([]*user.User, data.Page, error) {
+	var users []*user.User
+	total := 0
+
+	err := r.db.ReadWorkContext(ctx, func(q sqlrepo.QueryerContext) error {
+		if err := q.QueryRowContext(ctx, count, countArgs...).Scan(&total); err != nil {
+			return err
+		}
+
+		var err error
+		users, err = r.list(ctx, q, query, args...)
+		return err
+	})
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	n, result := page.Paginate(len(users), total, func(i int) string {
+		return users[i].Email
+	})
+
+	return users[:n], result, nil
+}
+
+//criteriaConditions is a helper function to translate criteria into query
+//conditions and their arguments.
+func criteriaConditions(criteria user.Criteria) ([]string, []interface{}) {
+	conditions, args := []string{}, []interface{}{}
+
+	if criteria.Enabled != nil {
+		conditions = append(conditions, "u.enabled = ?")
+		args = append(args, *criteria.Enabled)
+	}
+
+	if criteria.ClientId != nil {
+		conditions = append(
+			conditions,
+			fmt.Sprintf("EXISTS (SELECT 1 FROM %s AS uc WHERE uc.user_id = u.id AND uc.client_id = ?)", TableUserClients),
+		)
+		args = append(args, *criteria.ClientId)
+	}
+
+	if criteria.CreatedAfter != nil {
+		conditions = append(conditions, "u.created_at > ?")
+		args = append(args, sqlrepo.UTCTime{*criteria.CreatedAfter})
+	}
+
+	return conditions, args
+}
+
+//orderQuery is a helper function to take an unordered select query and add
+//ordering to it that the application expects.
+func orderQuery(query string) string {
+	return fmt.Sprintf("%s ORDER BY u.email ASC", query)
+}
+
+//list is a helper method to query for a list of Users with q, with their client
+//ids populated, independent of the actual query.
+func (r *Repo) list(ctx context.Context, q sqlrepo.QueryerContext, query string, args ...interface{}) ([]*user.User, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -94,7 +204,7 @@ func (r *Repo) List(ctx context.Context) ([]*user.User, error) {
 		result = append(result, u)
 	}
 
-	if err := r.populateClientIds(ctx, result); err != nil {
+	if err := r.populateMemberships(ctx, q, result); err != nil {
 		return nil, err
 	}
 
@@ -113,6 +223,7 @@ func scan(s sqlrepo.Scanner) (*user.User, error) {
 		&createdAt,
 		&updatedAt,
 		&user.Enabled,
+		&user.Version,
 	); err != nil {
 		return nil, err
 	}
@@ -122,11 +233,11 @@ func scan(s sqlrepo.Scanner) (*user.User, error) {
 	return user, nil
 }
 
-//populateCilentIds is a helper method to populate all client ids on all users
-//in users.
+//populateMemberships is a helper method to populate all Memberships on all
+//users in users with q.
 //
 //It should be called with all available users to avoid the N+1 problem.
-func (r *Repo) populateClientIds(ctx context.Context, users []*user.User) error {
+func (r *Repo) populateMemberships(ctx context.Context, q sqlrepo.QueryerContext, users []*user.User) error {
 	if len(users) == 0 {
 		return nil
 	}
@@ -139,7 +250,7 @@ func (r *Repo) populateClientIds(ctx context.Context, users []*user.User) error
 	}
 
 	placeholders, args := sqlrepo.IdsPlaceholdersArgs(ids)
-	rows, err := r.db.QueryContext(
+	rows, err := q.QueryContext(
 		ctx,
 		fmt.Sprintf("%s WHERE user_id IN (%s) ORDER BY user_id, client_id", SelectFromUserClients, placeholders),
 		args...,
@@ -150,26 +261,28 @@ func (r *Repo) populateClientIds(ctx context.Context, users []*user.User) error
 	defer rows.Close()
 
 	for rows.Next() {
-		userId, clientId, err := scanUserIdClientId(rows)
+		userId, m, err := scanMembership(rows)
 		if err != nil {
 			return err
 		}
 
 		user := usersById[userId]
-		user.ClientIds = append(user.ClientIds, clientId)
+		user.Memberships = append(user.Memberships, m)
 	}
 
 	return rows.Err()
 }
 
-func scanUserIdClientId(s sqlrepo.Scanner) (data.Id, data.Id, error) {
-	userId, clientId := data.EmptyId(), data.EmptyId()
-	err := s.Scan(&userId, &clientId)
-	return userId, clientId, err
+//scanMembership is a helper function to scan a user id and its Membership from
+//a sql row or rows.
+func scanMembership(s sqlrepo.Scanner) (data.Id, user.Membership, error) {
+	userId, m := data.EmptyId(), user.Membership{}
+	err := s.Scan(&userId, &m.ClientId, &m.Role)
+	return userId, m, err
 }
 
 //Add is the user.Repo implementation.
-func (r *Repo) Add(ctx context.Context, user user.User) error {
+func (r *Repo) Add(ctx context.Context, u user.User) error {
 	work := func(qec sqlrepo.QueryExecerContext) error {
 		_, err := qec.ExecContext(
 			ctx,
@@ -179,65 +292,210 @@ func (r *Repo) Add(ctx context.Context, user user.User) error {
 					email,
 					created_at,
 					updated_at,
-					enabled
-				) VALUES (?, ?, ?, ?, ?)`,
+					enabled,
+					version
+				) VALUES (?, ?, ?, ?, ?, ?)`,
 				Table,
 			),
-			user.Id,
-			user.Email,
-			sqlrepo.UTCTime{user.CreatedAt},
-			sqlrepo.UTCTime{user.UpdatedAt},
-			user.Enabled,
+			u.Id,
+			u.Email,
+			sqlrepo.UTCTime{u.CreatedAt},
+			sqlrepo.UTCTime{u.UpdatedAt},
+			u.Enabled,
+			u.Version,
 		)
 		if err != nil {
 			return err
 		}
-		return saveClientIds(qec, ctx, user)
+		if err := saveMemberships(qec, ctx, u); err != nil {
+			return err
+		}
+		return appendUpsert(ctx, qec, u)
 	}
 
-	return r.db.TxWorkContext(ctx, work)
+	return r.db.TranslateError(r.db.TxWorkContext(ctx, work), user.EntityName)
 }
 
 //Set is the user.Repo implementation.
-func (r *Repo) Set(ctx context.Context, user user.User) error {
+func (r *Repo) Set(ctx context.Context, u user.User) error {
 	work := func(qec sqlrepo.QueryExecerContext) error {
-		_, err := qec.ExecContext(
+		result, err := qec.ExecContext(
 			ctx,
 			fmt.Sprintf(
 				`UPDATE %s SET
 					email = ?,
 					created_at = ?,
 					updated_at = ?,
-					enabled = ?
-					WHERE id = ?`,
+					enabled = ?,
+					version = version + 1
+					WHERE id = ? AND version = ?`,
 				Table,
 			),
-			user.Email,
-			sqlrepo.UTCTime{user.CreatedAt},
-			sqlrepo.UTCTime{user.UpdatedAt},
-			user.Enabled,
-			user.Id,
+			u.Email,
+			sqlrepo.UTCTime{u.CreatedAt},
+			sqlrepo.UTCTime{u.UpdatedAt},
+			u.Enabled,
+			u.Id,
+			u.Version,
+		)
+		err = sqlrepo.RequireVersionedUpdate(
+			ctx,
+			qec,
+			result,
+			err,
+			Table,
+			u.Id,
+			data.NewNotFoundError(user.EntityName, "id", u.Id),
+			data.NewVersionMismatchError(user.EntityName, u.Id),
+		)
+		if err != nil {
+			return err
+		}
+		if err := saveMemberships(qec, ctx, u); err != nil {
+			return err
+		}
+
+		u.Version++
+		return appendUpsert(ctx, qec, u)
+	}
+
+	return r.db.TranslateError(r.db.TxWorkContext(ctx, work), user.EntityName)
+}
+
+//Remove is the user.Repo implementation.
+func (r *Repo) Remove(ctx context.Context, id data.Id, version *int) error {
+	work := func(qec sqlrepo.QueryExecerContext) error {
+		_, err := qec.ExecContext(
+			ctx,
+			fmt.Sprintf("DELETE FROM %s WHERE user_id = ?", TableUserClients),
+			id,
+		)
+		if err != nil {
+			return err
+		}
+
+		//The relationships deleted above are rolled back if the User is not.
+		err = sqlrepo.DeleteVersioned(
+			ctx,
+			qec,
+			Table,
+			id,
+			version,
+			data.NewNotFoundError(user.EntityName, "id", id),
+			data.NewVersionMismatchError(user.EntityName, id),
 		)
 		if err != nil {
 			return err
 		}
-		return saveClientIds(qec, ctx, user)
+		return changesql.Append(ctx, qec, change.Delete(user.EntityName, id))
 	}
 
 	return r.db.TxWorkContext(ctx, work)
 }
 
-//saveClientIds is a helper function to do a full update of client ids for a
-//single user.
-func saveClientIds(qec sqlrepo.QueryExecerContext, ctx context.Context, user user.User) error {
-	if err := removeClientIds(qec, ctx, user); err != nil {
+//AddClient is the user.Repo implementation.
+func (r *Repo) AddClient(ctx context.Context, userId data.Id, m user.Membership, version *int, updatedAt time.Time) (*user.User, bool, error) {
+	//An existing Membership is only updated, and so counted as a change, if its
+	//role differs so that concurrently adding the same Membership is not an error.
+	query := fmt.Sprintf(
+		`INSERT INTO %[1]s (user_id, client_id, role) VALUES (?, ?, ?)
+			ON CONFLICT (user_id, client_id) DO UPDATE SET role = excluded.role
+			WHERE %[1]s.role <> excluded.role`,
+		TableUserClients,
+	)
+	return r.changeClient(ctx, userId, version, updatedAt, query, userId, m.ClientId, m.Role)
+}
+
+//RemoveClient is the user.Repo implementation.
+func (r *Repo) RemoveClient(ctx context.Context, userId, clientId data.Id, version *int, updatedAt time.Time) (*user.User, bool, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id = ? AND client_id = ?", TableUserClients)
+	return r.changeClient(ctx, userId, version, updatedAt, query, userId, clientId)
+}
+
+//changeClient is a helper method to execute query, which adds, changes, or
+//removes a single Membership of the User with userId, and to update the User if
+//it affected a row.
+//
+//Only the single relationship is written so that concurrent changes to the
+//User's other Memberships are not lost.
+//If version is not nil, the User's update only matches it at version, and the
+//relationship is rolled back otherwise.
+func (r *Repo) changeClient(ctx context.Context, userId data.Id, version *int, updatedAt time.Time, query string, args ...interface{}) (*user.User, bool, error) {
+	var u *user.User
+	changed := false
+
+	work := func(qec sqlrepo.QueryExecerContext) error {
+		//An unknown User is reported as not found instead of as the foreign key
+		//violation of its relationship.
+		if _, err := r.get(ctx, qec, "id", userId); err != nil {
+			return err
+		}
+
+		result, err := qec.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		notFound := data.NewNotFoundError(user.EntityName, "id", userId)
+		mismatch := data.NewVersionMismatchError(user.EntityName, userId)
+
+		if changed = n > 0; changed {
+			update, updateArgs := fmt.Sprintf("UPDATE %s SET updated_at = ?, version = version + 1 WHERE id = ?", Table), []interface{}{sqlrepo.UTCTime{updatedAt}, userId}
+			if version != nil {
+				update += " AND version = ?"
+				updateArgs = append(updateArgs, *version)
+			}
+
+			result, err := qec.ExecContext(ctx, update, updateArgs...)
+			err = sqlrepo.RequireVersionedUpdate(ctx, qec, result, err, Table, userId, notFound, mismatch)
+			if err != nil {
+				return err
+			}
+		}
+
+		if u, err = r.get(ctx, qec, "id", userId); err != nil {
+			return err
+		}
+		if !changed {
+			//Nothing was written, but the request still requires the User's version.
+			if version != nil && u.Version != *version {
+				return mismatch
+			}
+			return nil
+		}
+		return appendUpsert(ctx, qec, *u)
+	}
+
+	if err := r.db.TranslateError(r.db.TxWorkContext(ctx, work), user.EntityName); err != nil {
+		return nil, false, err
+	}
+	return u, changed, nil
+}
+
+//appendUpsert is a helper function to record u as stored in the change feed.
+func appendUpsert(ctx context.Context, qec sqlrepo.QueryExecerContext, u user.User) error {
+	c, err := change.Upsert(user.EntityName, u.Id, u)
+	if err != nil {
+		return err
+	}
+	return changesql.Append(ctx, qec, c)
+}
+
+//saveMemberships is a helper function to do a full update of the Memberships
+//of a single user.
+func saveMemberships(qec sqlrepo.QueryExecerContext, ctx context.Context, user user.User) error {
+	if err := removeMemberships(qec, ctx, user); err != nil {
 		return err
 	}
-	return setClientIds(qec, ctx, user)
+	return setMemberships(qec, ctx, user)
 }
 
-//removeClientIds removes all client ids for user.
-func removeClientIds(qec sqlrepo.QueryExecerContext, ctx context.Context, user user.User) error {
+//removeMemberships removes all Memberships for user.
+func removeMemberships(qec sqlrepo.QueryExecerContext, ctx context.Context, user user.User) error {
 	_, err := qec.ExecContext(
 		ctx,
 		fmt.Sprintf("DELETE FROM %s WHERE user_id = ?", TableUserClients),
@@ -246,21 +504,21 @@ func removeClientIds(qec sqlrepo.QueryExecerContext, ctx context.Context, user u
 	return err
 }
 
-//setClientIds adds all client ids for user.
-func setClientIds(qec sqlrepo.QueryExecerContext, ctx context.Context, user user.User) error {
-	if len(user.ClientIds) == 0 {
+//setMemberships adds all Memberships for user.
+func setMemberships(qec sqlrepo.QueryExecerContext, ctx context.Context, user user.User) error {
+	if len(user.Memberships) == 0 {
 		return nil
 	}
 
 	query := fmt.Sprintf(
-		"INSERT INTO %s (user_id, client_id) VALUES %s",
+		"INSERT INTO %s (user_id, client_id, role) VALUES %s",
 		TableUserClients,
-		sqlrepo.List("(?,?)", len(user.ClientIds)),
+		sqlrepo.List("(?,?,?)", len(user.Memberships)),
 	)
 
-	args := make([]interface{}, 0, 2*len(user.ClientIds))
-	for _, clientId := range user.ClientIds {
-		args = append(args, user.Id, clientId)
+	args := make([]interface{}, 0, 3*len(user.Memberships))
+	for _, m := range user.Memberships {
+		args = append(args, user.Id, m.ClientId, m.Role)
 	}
 
 	_, err := qec.ExecContext(ctx, query, args...)
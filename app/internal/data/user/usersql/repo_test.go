@@ -0,0 +1,19 @@
+package usersql_test
+
+import (
+	"testing"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client/clientsql"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/sqlrepo/sqlrepotest"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user/usersql"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user/usertest"
+)
+
+func TestRepo(t *testing.T) {
+	usertest.TestRepo(t, func(t *testing.T) (user.Repo, client.Repo) {
+		repo := sqlrepotest.Open(t)
+		return usersql.New(repo), clientsql.New(repo)
+	})
+}
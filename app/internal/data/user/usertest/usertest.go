@@ -0,0 +1,451 @@
+package usertest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+)
+
+//NewRepos is a function type that returns new, empty repositories that share
+//their storage so that Users can have Memberships of the Clients in clients.
+type NewRepos func(t *testing.T) (users user.Repo, clients client.Repo)
+
+//TestRepo tests that the repositories returned by newRepos have the semantics
+//described by user.Repo, so that every implementation behaves the same.
+func TestRepo(t *testing.T, newRepos NewRepos) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, users user.Repo, clients client.Repo)
+	}{
+		{"Add", testAdd},
+		{"AddConflict", testAddConflict},
+		{"AddUnknownClient", testAddUnknownClient},
+		{"Set", testSet},
+		{"SetConflict", testSetConflict},
+		{"Remove", testRemove},
+		{"List", testList},
+		{"ListPage", testListPage},
+		{"ListPageCriteria", testListPageCriteria},
+		{"ListByClient", testListByClient},
+		{"AddClient", testAddClient},
+		{"RemoveClient", testRemoveClient},
+		{"ClientVersion", testClientVersion},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users, clients := newRepos(t)
+			test.test(t, users, clients)
+		})
+	}
+}
+
+func testAdd(t *testing.T, users user.Repo, clients client.Repo) {
+	c := AddClient(t, clients, "acme")
+	u := NewUser(t, "a@example.com", user.Membership{ClientId: c.Id, Role: user.RoleAdmin})
+
+	if err := users.Add(context.Background(), *u); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := users.Get(context.Background(), u.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireUser(t, got, u)
+
+	got, err = users.GetEmail(context.Background(), u.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireUser(t, got, u)
+
+	if _, err := users.Get(context.Background(), NewId(t)); !data.IsNotFound(err) {
+		t.Errorf("Get(unknown) error = %v, want a not found error", err)
+	}
+	if _, err := users.GetEmail(context.Background(), "b@example.com"); !data.IsNotFound(err) {
+		t.Errorf("GetEmail(unknown) error = %v, want a not found error", err)
+	}
+}
+
+func testAddConflict(t *testing.T, users user.Repo, clients client.Repo) {
+	u := AddUser(t, users, "a@example.com")
+
+	other := NewUser(t, u.Email)
+	if err := users.Add(context.Background(), *other); !data.IsConflict(err) {
+		t.Errorf("Add(same email) error = %v, want a conflict error", err)
+	}
+
+	if err := users.Add(context.Background(), *u); !data.IsConflict(err) {
+		t.Errorf("Add(same id) error = %v, want a conflict error", err)
+	}
+}
+
+func testAddUnknownClient(t *testing.T, users user.Repo, clients client.Repo) {
+	u := NewUser(t, "a@example.com", user.Membership{ClientId: NewId(t), Role: user.DefaultRole})
+
+	if err := users.Add(context.Background(), *u); err == nil {
+		t.Fatal("Add(unknown client) error = nil, want an error")
+	}
+	if _, err := users.Get(context.Background(), u.Id); !data.IsNotFound(err) {
+		t.Errorf("Get() error = %v, want a not found error after a failed Add", err)
+	}
+}
+
+func testSet(t *testing.T, users user.Repo, clients client.Repo) {
+	a, b := AddClient(t, clients, "a"), AddClient(t, clients, "b")
+	u := AddUser(t, users, "a@example.com", user.Membership{ClientId: a.Id, Role: user.RoleViewer})
+
+	u.Email = "b@example.com"
+	u.Enabled = false
+	u.UpdatedAt = u.UpdatedAt.Add(time.Minute)
+	u.Memberships = []user.Membership{{ClientId: b.Id, Role: user.RoleEditor}}
+
+	if err := users.Set(context.Background(), *u); err != nil {
+		t.Fatal(err)
+	}
+	u.Version++
+
+	got, err := users.Get(context.Background(), u.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireUser(t, got, u)
+
+	stale := *u
+	stale.Version--
+	if err := users.Set(context.Background(), stale); !data.IsVersionMismatch(err) {
+		t.Errorf("Set(stale version) error = %v, want a version mismatch error", err)
+	}
+
+	if err := users.Set(context.Background(), *NewUser(t, "c@example.com")); !data.IsNotFound(err) {
+		t.Errorf("Set(unknown) error = %v, want a not found error", err)
+	}
+}
+
+func testSetConflict(t *testing.T, users user.Repo, clients client.Repo) {
+	AddUser(t, users, "a@example.com")
+	u := AddUser(t, users, "b@example.com")
+
+	u.Email = "a@example.com"
+	if err := users.Set(context.Background(), *u); !data.IsConflict(err) {
+		t.Errorf("Set(taken email) error = %v, want a conflict error", err)
+	}
+}
+
+func testRemove(t *testing.T, users user.Repo, clients client.Repo) {
+	c := AddClient(t, clients, "acme")
+	u := AddUser(t, users, "a@example.com", user.Membership{ClientId: c.Id, Role: user.DefaultRole})
+
+	stale := u.Version + 1
+	if err := users.Remove(context.Background(), u.Id, &stale); !data.IsVersionMismatch(err) {
+		t.Errorf("Remove(stale version) error = %v, want a version mismatch error", err)
+	}
+
+	if err := users.Remove(context.Background(), u.Id, &u.Version); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.Get(context.Background(), u.Id); !data.IsNotFound(err) {
+		t.Errorf("Get(removed) error = %v, want a not found error", err)
+	}
+	if err := users.Remove(context.Background(), u.Id, nil); !data.IsNotFound(err) {
+		t.Errorf("Remove(removed) error = %v, want a not found error", err)
+	}
+
+	//The Client remains and has no Users.
+	if _, err := clients.Get(context.Background(), c.Id); err != nil {
+		t.Errorf("Get(client) error = %v, want nil", err)
+	}
+	requireEmails(t, listByClient(t, users, c.Id))
+}
+
+func testList(t *testing.T, users user.Repo, clients client.Repo) {
+	for _, email := range []string{"c@example.com", "a@example.com", "b@example.com"} {
+		AddUser(t, users, email)
+	}
+
+	list, err := users.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireEmails(t, list, "a@example.com", "b@example.com", "c@example.com")
+}
+
+func testListPage(t *testing.T, users user.Repo, clients client.Repo) {
+	for i := 4; i >= 0; i-- {
+		AddUser(t, users, fmt.Sprintf("%d@example.com", i))
+	}
+
+	page := data.PageRequest{Limit: 2}
+	for _, want := range [][]string{
+		{"0@example.com", "1@example.com"},
+		{"2@example.com", "3@example.com"},
+		{"4@example.com"},
+	} {
+		list, p, err := users.ListPage(context.Background(), user.Criteria{}, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		requireEmails(t, list, want...)
+
+		if p.Total != 5 {
+			t.Errorf("Total = %d, want 5", p.Total)
+		}
+		if last := want[len(want)-1]; (p.Next == "") != (last == "4@example.com") {
+			t.Errorf("Next = %q after %s", p.Next, last)
+		}
+		page.Cursor = p.Next
+	}
+
+	_, _, err := users.ListPage(context.Background(), user.Criteria{}, data.PageRequest{Limit: 2, Cursor: "!"})
+	if err != data.ErrInvalidCursor {
+		t.Errorf("ListPage(invalid cursor) error = %v, want %v", err, data.ErrInvalidCursor)
+	}
+}
+
+func testListPageCriteria(t *testing.T, users user.Repo, clients client.Repo) {
+	a, b := AddClient(t, clients, "a"), AddClient(t, clients, "b")
+
+	enabled := NewUser(t, "enabled@example.com", user.Membership{ClientId: a.Id, Role: user.DefaultRole})
+	disabled := NewUser(t, "disabled@example.com", user.Membership{ClientId: b.Id, Role: user.DefaultRole})
+	disabled.Enabled = false
+	disabled.CreatedAt = enabled.CreatedAt.Add(-time.Hour)
+	for _, u := range []*user.User{enabled, disabled} {
+		if err := users.Add(context.Background(), *u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	yes, createdAfter := true, enabled.CreatedAt.Add(-time.Minute)
+
+	tests := []struct {
+		name     string
+		criteria user.Criteria
+		want     []string
+	}{
+		{"zero", user.Criteria{}, []string{"disabled@example.com", "enabled@example.com"}},
+		{"Enabled", user.Criteria{Enabled: &yes}, []string{"enabled@example.com"}},
+		{"ClientId", user.Criteria{ClientId: &b.Id}, []string{"disabled@example.com"}},
+		{"CreatedAfter", user.Criteria{CreatedAfter: &createdAfter}, []string{"enabled@example.com"}},
+		{"all", user.Criteria{Enabled: &yes, ClientId: &b.Id}, nil},
+	}
+	for _, test := range tests {
+		list, p, err := users.ListPage(context.Background(), test.criteria, data.PageRequest{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		requireEmails(t, list, test.want...)
+		if p.Total != len(test.want) {
+			t.Errorf("%s: Total = %d, want %d", test.name, p.Total, len(test.want))
+		}
+	}
+}
+
+func testListByClient(t *testing.T, users user.Repo, clients client.Repo) {
+	a, b := AddClient(t, clients, "a"), AddClient(t, clients, "b")
+
+	AddUser(t, users, "c@example.com", user.Membership{ClientId: a.Id, Role: user.DefaultRole})
+	AddUser(t, users, "b@example.com", user.Membership{ClientId: b.Id, Role: user.DefaultRole})
+	AddUser(t, users, "a@example.com",
+		user.Membership{ClientId: b.Id, Role: user.DefaultRole},
+		user.Membership{ClientId: a.Id, Role: user.RoleAdmin},
+	)
+
+	requireEmails(t, listByClient(t, users, a.Id), "a@example.com", "c@example.com")
+	requireEmails(t, listByClient(t, users, b.Id), "a@example.com", "b@example.com")
+	requireEmails(t, listByClient(t, users, NewId(t)))
+}
+
+func testAddClient(t *testing.T, users user.Repo, clients client.Repo) {
+	a, b := AddClient(t, clients, "a"), AddClient(t, clients, "b")
+	u := AddUser(t, users, "a@example.com", user.Membership{ClientId: a.Id, Role: user.RoleViewer})
+	updatedAt := u.UpdatedAt.Add(time.Minute)
+
+	got, changed, err := users.AddClient(context.Background(), u.Id, user.Membership{ClientId: b.Id, Role: user.RoleEditor}, nil, updatedAt)
+	if err != nil || !changed {
+		t.Fatalf("AddClient(new) = %v, %v, want a change", changed, err)
+	}
+	u.Memberships = append(u.Memberships, user.Membership{ClientId: b.Id, Role: user.RoleEditor})
+	u.UpdatedAt = updatedAt
+	u.Version++
+	requireUser(t, got, u)
+
+	//Adding the same Membership again changes nothing.
+	got, changed, err = users.AddClient(context.Background(), u.Id, user.Membership{ClientId: b.Id, Role: user.RoleEditor}, nil, updatedAt.Add(time.Minute))
+	if err != nil || changed {
+		t.Fatalf("AddClient(same) = %v, %v, want no change", changed, err)
+	}
+	requireUser(t, got, u)
+
+	//Adding a Membership with a different Role replaces it.
+	got, changed, err = users.AddClient(context.Background(), u.Id, user.Membership{ClientId: a.Id, Role: user.RoleAdmin}, nil, updatedAt)
+	if err != nil || !changed {
+		t.Fatalf("AddClient(new role) = %v, %v, want a change", changed, err)
+	}
+	u.Memberships[0].Role = user.RoleAdmin
+	u.Version++
+	requireUser(t, got, u)
+
+	stored, err := users.Get(context.Background(), u.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireUser(t, stored, u)
+
+	if _, _, err := users.AddClient(context.Background(), NewId(t), user.Membership{ClientId: a.Id, Role: user.DefaultRole}, nil, updatedAt); !data.IsNotFound(err) {
+		t.Errorf("AddClient(unknown user) error = %v, want a not found error", err)
+	}
+}
+
+func testRemoveClient(t *testing.T, users user.Repo, clients client.Repo) {
+	a, b := AddClient(t, clients, "a"), AddClient(t, clients, "b")
+	u := AddUser(t, users, "a@example.com",
+		user.Membership{ClientId: a.Id, Role: user.RoleViewer},
+		user.Membership{ClientId: b.Id, Role: user.RoleAdmin},
+	)
+	updatedAt := u.UpdatedAt.Add(time.Minute)
+
+	got, changed, err := users.RemoveClient(context.Background(), u.Id, a.Id, nil, updatedAt)
+	if err != nil || !changed {
+		t.Fatalf("RemoveClient() = %v, %v, want a change", changed, err)
+	}
+	u.Memberships = u.Memberships[1:]
+	u.UpdatedAt = updatedAt
+	u.Version++
+	requireUser(t, got, u)
+
+	got, changed, err = users.RemoveClient(context.Background(), u.Id, a.Id, nil, updatedAt.Add(time.Minute))
+	if err != nil || changed {
+		t.Fatalf("RemoveClient(again) = %v, %v, want no change", changed, err)
+	}
+	requireUser(t, got, u)
+
+	if _, _, err := users.RemoveClient(context.Background(), NewId(t), a.Id, nil, updatedAt); !data.IsNotFound(err) {
+		t.Errorf("RemoveClient(unknown user) error = %v, want a not found error", err)
+	}
+}
+
+func testClientVersion(t *testing.T, users user.Repo, clients client.Repo) {
+	a, b := AddClient(t, clients, "a"), AddClient(t, clients, "b")
+	u := AddUser(t, users, "a@example.com", user.Membership{ClientId: a.Id, Role: user.DefaultRole})
+
+	stale := u.Version + 1
+	if _, _, err := users.AddClient(context.Background(), u.Id, user.Membership{ClientId: b.Id, Role: user.DefaultRole}, &stale, u.UpdatedAt); !data.IsVersionMismatch(err) {
+		t.Errorf("AddClient(stale version) error = %v, want a version mismatch error", err)
+	}
+	if _, _, err := users.RemoveClient(context.Background(), u.Id, a.Id, &stale, u.UpdatedAt); !data.IsVersionMismatch(err) {
+		t.Errorf("RemoveClient(stale version) error = %v, want a version mismatch error", err)
+	}
+
+	stored, err := users.Get(context.Background(), u.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireUser(t, stored, u)
+
+	if _, changed, err := users.AddClient(context.Background(), u.Id, user.Membership{ClientId: b.Id, Role: user.DefaultRole}, &u.Version, u.UpdatedAt); err != nil || !changed {
+		t.Errorf("AddClient(current version) = %v, %v, want a change", changed, err)
+	}
+}
+
+//NewId returns a new data.Id or fails t.
+func NewId(t *testing.T) data.Id {
+	id, err := data.NewId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+//NewUser returns a new enabled User at version 1 with email and memberships.
+//Its times are truncated to microseconds, which all repositories can store.
+func NewUser(t *testing.T, email string, memberships ...user.Membership) *user.User {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	return &user.User{
+		Id:          NewId(t),
+		Email:       email,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Enabled:     true,
+		Memberships: memberships,
+		Version:     1,
+	}
+}
+
+//AddUser adds NewUser(t, email, memberships...) to users and returns it.
+func AddUser(t *testing.T, users user.Repo, email string, memberships ...user.Membership) *user.User {
+	u := NewUser(t, email, memberships...)
+	if err := users.Add(context.Background(), *u); err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+//AddClient adds a new Client at version 1 with name to clients and returns it.
+func AddClient(t *testing.T, clients client.Repo, name string) *client.Client {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	c := &client.Client{
+		Id:        NewId(t),
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version:   1,
+	}
+	if err := clients.Add(context.Background(), *c); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+//listByClient returns the first page of users that belong to the Client with
+//clientId.
+func listByClient(t *testing.T, users user.Repo, clientId data.Id) []*user.User {
+	list, _, err := users.ListByClient(context.Background(), clientId, data.PageRequest{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
+
+//requireUser fails t if got is not equal to want.
+//Memberships are compared without regard to their order.
+func requireUser(t *testing.T, got, want *user.User) {
+	t.Helper()
+
+	if !got.Id.Equal(want.Id) ||
+		got.Email != want.Email ||
+		!got.CreatedAt.Equal(want.CreatedAt) ||
+		!got.UpdatedAt.Equal(want.UpdatedAt) ||
+		got.Enabled != want.Enabled ||
+		got.Version != want.Version ||
+		len(got.Memberships) != len(want.Memberships) {
+		t.Fatalf("user = %+v, want %+v", got, want)
+	}
+
+	for _, m := range want.Memberships {
+		if other, ok := got.Membership(m.ClientId); !ok || other.Role != m.Role {
+			t.Fatalf("user memberships = %v, want %v", got.Memberships, want.Memberships)
+		}
+	}
+}
+
+//requireEmails fails t if the Emails of users are not emails in order.
+func requireEmails(t *testing.T, users []*user.User, emails ...string) {
+	t.Helper()
+
+	got := make([]string, len(users))
+	for i, u := range users {
+		got[i] = u.Email
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(emails) || len(got) != len(emails) {
+		t.Fatalf("emails = %v, want %v", got, emails)
+	}
+}
@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//DeliveryEntityName is the name used to refer to Delivery entities in errors
+//and other descriptions.
+const DeliveryEntityName = "webhook delivery"
+
+//Statuses of a Delivery.
+const (
+	//StatusPending Deliveries will be attempted at their NextAttemptAt time.
+	StatusPending = "pending"
+
+	//StatusSucceeded Deliveries received a 2xx response.
+	StatusSucceeded = "succeeded"
+
+	//StatusFailed Deliveries will not be attempted again.
+	StatusFailed = "failed"
+)
+
+//Delivery is a domain type that records sending a single event to a Webhook.
+type Delivery struct {
+	//Id is the Delivery's id.
+	Id data.Id
+
+	//WebhookId is the Id of the Webhook the event is sent to.
+	WebhookId data.Id
+
+	//Event is the name of the event, e.g. "user.created".
+	Event string
+
+	//Payload is the request body sent to the Webhook.
+	Payload json.RawMessage
+
+	//Status is one of StatusPending, StatusSucceeded, or StatusFailed.
+	Status string
+
+	//Attempts is the number of times sending has been attempted.
+	Attempts int
+
+	//NextAttemptAt is the time at which the next attempt should happen.
+	//It is nil if Status is not StatusPending.
+	NextAttemptAt *time.Time
+
+	//LastAttemptAt is the time of the last attempt, or nil if there have been none.
+	LastAttemptAt *time.Time
+
+	//ResponseStatus is the http status of the last attempt's response.
+	//It is 0 if the last attempt did not receive a response.
+	ResponseStatus int
+
+	//LastError describes why the last attempt failed.
+	//It is empty if the last attempt succeeded or there have been none.
+	LastError string
+
+	//CreatedAt is the time at which the Delivery was created.
+	CreatedAt time.Time
+
+	//UpdatedAt is the time at which the Delivery was last updated.
+	UpdatedAt time.Time
+}
+
+//DeliveryKey returns the sort key of d.
+//Deliveries are sorted by their CreatedAt time and then by their Id.
+func DeliveryKey(d *Delivery) string {
+	return data.TimeKey(d.CreatedAt, d.Id)
+}
+
+//NewDelivery returns a new pending Delivery of the event with name and payload
+//to the Webhook with webhookId that should be attempted immediately.
+func NewDelivery(webhookId data.Id, name string, payload json.RawMessage) (*Delivery, error) {
+	id, err := data.NewId()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	return &Delivery{
+		Id:            id,
+		WebhookId:     webhookId,
+		Event:         name,
+		Payload:       payload,
+		Status:        StatusPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event"
+)
+
+//Dispatcher is an event.Subscriber that creates a Delivery of each event to
+//every Webhook that Subscribes to it.
+type Dispatcher struct {
+	//Webhooks is where subscribed Webhooks are retrieved from.
+	Webhooks QueryRepo
+
+	//Deliveries is where new Deliveries are added.
+	Deliveries DeliveryRepo
+
+	//Data returns the marshalable representation of an event sent as the Data
+	//of its Envelope.
+	//If nil, the event itself is sent.
+	Data func(e event.Event) (interface{}, error)
+}
+
+//Envelope is the request body sent to Webhooks.
+type Envelope struct {
+	//Id identifies the event to the Webhook so that it can ignore an event it
+	//has already received.
+	//It is the Id the event was published with, see event.ContextWithId, and is
+	//the same for all Deliveries and redeliveries of the event.
+	Id data.Id `json:"id"`
+
+	//Event is the name of the event.
+	Event string `json:"event"`
+
+	//CreatedAt is the time at which the event was dispatched to the Webhook.
+	CreatedAt time.Time `json:"created_at"`
+
+	//Data is the event as returned by Dispatcher.Data.
+	Data interface{} `json:"data"`
+}
+
+//HandleEvent is the event.Subscriber implementation.
+//Events published without an Id are given a new one.
+func (d *Dispatcher) HandleEvent(ctx context.Context, e event.Event) error {
+	webhooks, err := d.Webhooks.ListSubscribed(ctx, e.EventName())
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	id, ok := event.IdFromContext(ctx)
+	if !ok {
+		if id, err = data.NewId(); err != nil {
+			return err
+		}
+	}
+
+	var body interface{} = e
+	if d.Data != nil {
+		if body, err = d.Data(e); err != nil {
+			return err
+		}
+	}
+
+	deliveries := make([]Delivery, len(webhooks))
+	for i, w := range webhooks {
+		delivery, err := NewDelivery(w.Id, e.EventName(), nil)
+		if err != nil {
+			return err
+		}
+
+		delivery.Payload, err = json.Marshal(&Envelope{
+			Id:        id,
+			Event:     e.EventName(),
+			CreatedAt: delivery.CreatedAt,
+			Data:      body,
+		})
+		if err != nil {
+			return err
+		}
+
+		deliveries[i] = *delivery
+	}
+
+	return d.Deliveries.Add(ctx, deliveries...)
+}
@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//QueryRepo provides methods for retrieving Webhooks.
+type QueryRepo interface {
+	//Get should return the Webhook whose Id equals id.
+	//
+	//A *data.NotFoundError should be returned if the Webhook does not exist.
+	//Another error should be returned if there was an error attempting to load the Webhook.
+	Get(ctx context.Context, id data.Id) (*Webhook, error)
+
+	//ListPage should return the page of Webhooks described by page.
+	//They should be sorted by their CreatedAt time and then by their Id.
+	//The Page's Next cursor is keyed on the Key of the last Webhook.
+	//
+	//data.ErrInvalidCursor should be returned if page.Cursor is invalid.
+	ListPage(ctx context.Context, page data.PageRequest) ([]*Webhook, data.Page, error)
+
+	//ListSubscribed should return all Webhooks that Subscribe to the event with name.
+	ListSubscribed(ctx context.Context, name string) ([]*Webhook, error)
+}
+
+//Repo provides methods for creating, updating, and removing Webhooks as well
+//as promotes the QueryRepo interface.
+type Repo interface {
+	//QueryRepo is promoted here to indicate a Repo contains all query methods.
+	QueryRepo
+
+	//Add should add w to the underlying storage repository.
+	Add(ctx context.Context, w Webhook) error
+
+	//Set should update all stored fields of w in the underlying storage repository.
+	//The update should use w.Id for determining which entity to update.
+	//
+	//The update should only happen if the stored version equals w.Version, and it
+	//should increment the stored version by one.
+	//A *data.VersionMismatchError should be returned if the stored version differs,
+	//and a *data.NotFoundError should be returned if the Webhook does not exist.
+	Set(ctx context.Context, w Webhook) error
+
+	//Remove should remove the Webhook with id, and all of its Deliveries, from
+	//the underlying storage repository.
+	//If version is not nil, the Webhook should only be removed if its stored
+	//version equals *version, in the same statement that removes it.
+	//A *data.NotFoundError should be returned if the Webhook does not exist, and a
+	//*data.VersionMismatchError should be returned if its version differs.
+	Remove(ctx context.Context, id data.Id, version *int) error
+}
+
+//DeliveryQueryRepo provides methods for retrieving Deliveries.
+type DeliveryQueryRepo interface {
+	//Get should return the Delivery whose Id equals id.
+	//
+	//A *data.NotFoundError should be returned if the Delivery does not exist.
+	Get(ctx context.Context, id data.Id) (*Delivery, error)
+
+	//ListPage should return the page of Deliveries to the Webhook with webhookId
+	//described by page.
+	//They should be sorted by their CreatedAt time and then by their Id.
+	//The Page's Next cursor is keyed on the DeliveryKey of the last Delivery.
+	//
+	//data.ErrInvalidCursor should be returned if page.Cursor is invalid.
+	ListPage(ctx context.Context, webhookId data.Id, page data.PageRequest) ([]*Delivery, data.Page, error)
+}
+
+//DeliveryRepo provides methods for creating and updating Deliveries as well
+//as promotes the DeliveryQueryRepo interface.
+type DeliveryRepo interface {
+	//DeliveryQueryRepo is promoted here to indicate a DeliveryRepo contains all
+	//query methods.
+	DeliveryQueryRepo
+
+	//Add should add deliveries to the underlying storage repository.
+	//A *data.NotFoundError should be returned if a Delivery's Webhook does not exist.
+	Add(ctx context.Context, deliveries ...Delivery) error
+
+	//ListDue should return up to limit pending Deliveries whose NextAttemptAt
+	//is not after now.
+	//They should be sorted by their NextAttemptAt time and then by their Id.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*Delivery, error)
+
+	//Set should update all stored fields of d in the underlying storage repository.
+	//A *data.NotFoundError should be returned if the Delivery does not exist.
+	Set(ctx context.Context, d Delivery) error
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+//Headers sent with every Delivery attempt.
+const (
+	//HeaderId is the Id of the Webhook.
+	HeaderId = "X-Webhook-Id"
+
+	//HeaderDelivery is the Id of the Delivery.
+	HeaderDelivery = "X-Webhook-Delivery"
+
+	//HeaderEvent is the name of the event.
+	HeaderEvent = "X-Webhook-Event"
+
+	//HeaderTimestamp is the unix time of the attempt, which is also signed.
+	HeaderTimestamp = "X-Webhook-Timestamp"
+
+	//HeaderSignature is the result of Sign for the attempt.
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+//signaturePrefix identifies the signing algorithm in a signature.
+const signaturePrefix = "sha256="
+
+//secretSize is the number of random bytes in a secret from NewSecret.
+const secretSize = 32
+
+//Sign returns the signature of body sent at timestamp with secret.
+//It is "sha256=" followed by the hex encoded HMAC-SHA256 of timestamp, ".",
+//and body keyed by secret.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+//Verify returns whether or not signature is the result of Sign(secret, timestamp, body).
+//Receivers of Webhooks should use it to check the HeaderSignature header.
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
+
+//NewSecret returns a new random secret for a Webhook.
+func NewSecret() (string, error) {
+	p := make([]byte, secretSize)
+	if _, err := rand.Read(p); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(p), nil
+}
@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//EntityName is the name used to refer to Webhook entities in errors and other
+//descriptions.
+const EntityName = "webhook"
+
+//ErrInvalidURL is a sentinel error indicating that a Webhook's URL is not an
+//absolute http or https URL.
+var ErrInvalidURL = errors.New("webhook: url must be an absolute http or https url")
+
+//Webhook is a domain type that subscribes a URL to the application's events.
+type Webhook struct {
+	//Id is the Webhook's id.
+	Id data.Id
+
+	//URL is where events are POSTed.
+	URL string
+
+	//Events is the names of the events the Webhook receives.
+	//An empty Events receives all events.
+	Events []string
+
+	//Secret is the key used to sign the events sent to URL.
+	//It is never marshaled so that it does not leak into logs.
+	Secret string `json:"-"`
+
+	//Enabled is whether or not events are sent to URL.
+	Enabled bool
+
+	//CreatedAt is the time at which the Webhook was created.
+	CreatedAt time.Time
+
+	//UpdatedAt is the time at which the Webhook was last updated.
+	UpdatedAt time.Time
+
+	//Version is incremented every time the Webhook is updated.
+	//It starts at 1.
+	Version int
+}
+
+//Subscribes returns whether or not w should receive the event with name.
+//Disabled Webhooks do not receive any events.
+func (w *Webhook) Subscribes(name string) bool {
+	if !w.Enabled {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return true
+	}
+
+	for _, event := range w.Events {
+		if event == name {
+			return true
+		}
+	}
+	return false
+}
+
+//Key returns the sort key of w.
+//Webhooks are sorted by their CreatedAt time and then by their Id.
+func Key(w *Webhook) string {
+	return data.TimeKey(w.CreatedAt, w.Id)
+}
+
+//ValidateURL returns ErrInvalidURL if rawURL cannot be used as a Webhook's URL.
+func ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return ErrInvalidURL
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrInvalidURL
+	}
+	return nil
+}
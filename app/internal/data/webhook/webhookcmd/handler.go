@@ -0,0 +1,227 @@
+package webhookcmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
+	"github.com/gogolfing/cbus"
+)
+
+//Handler is a handler type that understands how to work with Webhook commands.
+type Handler struct {
+	//Webhooks is the Webhook repository to use within Command handling.
+	Webhooks webhook.Repo
+
+	//Deliveries is the Delivery repository to use within Command handling.
+	Deliveries webhook.DeliveryRepo
+}
+
+//CreateWebhook attempts to create a new Webhook and add it to h.Webhooks.
+//Cmd must be of type *CreateWebhookCommand.
+//The result, if not nil and without error, will be a *webhook.Webhook.
+//webhook.ErrInvalidURL is returned if the command's URL is invalid.
+func (h *Handler) CreateWebhook(ctx context.Context, cmd cbus.Command) (interface{}, error) {
+	createWebhook := cmd.(*CreateWebhookCommand)
+
+	w, err := createWebhook.newWebhook()
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.Webhooks.Add(ctx, *w)
+
+	return w, err
+}
+
+//CreateWebhookCommand is a Command that should be used to create a new Webhook.
+type CreateWebhookCommand struct {
+	//URL is the new Webhook's URL.
+	URL string
+
+	//Events is the new Webhook's Events.
+	Events []string
+
+	//Secret is the new Webhook's Secret.
+	//A random secret is generated if it is empty.
+	Secret string `json:"-"`
+
+	//Enabled is the new Webhook's Enabled field.
+	Enabled bool
+}
+
+func (c *CreateWebhookCommand) newWebhook() (*webhook.Webhook, error) {
+	if err := webhook.ValidateURL(c.URL); err != nil {
+		return nil, err
+	}
+
+	id, err := data.NewId()
+	if err != nil {
+		return nil, err
+	}
+
+	secret := c.Secret
+	if secret == "" {
+		if secret, err = webhook.NewSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	return &webhook.Webhook{
+		Id:        id,
+		URL:       c.URL,
+		Events:    c.Events,
+		Secret:    secret,
+		Enabled:   c.Enabled,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version:   1,
+	}, nil
+}
+
+//UpdateWebhook attempts to retrieve and set a Webhook using h.Webhooks.
+//Cmd must be of type *UpdateWebhookCommand.
+//The result, if not nil and without error, will be a *webhook.Webhook.
+//A *data.NotFoundError is returned if the Webhook does not exist.
+//A *data.VersionMismatchError is returned if the Webhook is not at the command's
+//Version or if it is changed concurrently.
+//webhook.ErrInvalidURL is returned if the command's URL is invalid.
+func (h *Handler) UpdateWebhook(ctx context.Context, cmd cbus.Command) (interface{}, error) {
+	updateWebhook := cmd.(*UpdateWebhookCommand)
+
+	if updateWebhook.URL != nil {
+		if err := webhook.ValidateURL(*updateWebhook.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	w, err := h.getVersion(ctx, updateWebhook.Id, updateWebhook.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	updateWebhook.updateWebhook(w)
+
+	if err := h.Webhooks.Set(ctx, *w); err != nil {
+		return nil, err
+	}
+	w.Version++
+
+	return w, nil
+}
+
+//getVersion is a helper method to get the Webhook with id from h.Webhooks and
+//make sure it is at version if version is not nil.
+func (h *Handler) getVersion(ctx context.Context, id data.Id, version *int) (*webhook.Webhook, error) {
+	w, err := h.Webhooks.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != nil && w.Version != *version {
+		return nil, data.NewVersionMismatchError(webhook.EntityName, id)
+	}
+
+	return w, nil
+}
+
+//UpdateWebhookCommand is a Command to update a Webhook.
+type UpdateWebhookCommand struct {
+	//Id is the Id of the Webhook to update.
+	Id data.Id
+
+	//URL, if not nil, is the url to set on the Webhook.
+	URL *string
+
+	//Events, if not nil, is the event names to set on the Webhook.
+	Events *[]string
+
+	//Secret, if not nil, is the secret to set on the Webhook.
+	Secret *string `json:"-"`
+
+	//Enabled, if not nil, is the enabled value to set on the Webhook.
+	Enabled *bool
+
+	//Version, if not nil, is the Version the Webhook must be at to be updated.
+	Version *int
+}
+
+func (c *UpdateWebhookCommand) updateWebhook(w *webhook.Webhook) {
+	//Do stuff for all Webhook updates.
+	w.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	//Do stuff specific to this instance of c.
+	if c.URL != nil {
+		w.URL = *c.URL
+	}
+	if c.Events != nil {
+		w.Events = *c.Events
+	}
+	if c.Secret != nil {
+		w.Secret = *c.Secret
+	}
+	if c.Enabled != nil {
+		w.Enabled = *c.Enabled
+	}
+}
+
+//DeleteWebhook attempts to remove a Webhook, and all of its Deliveries, from
+//h.Webhooks.
+//Cmd must be of type *DeleteWebhookCommand.
+//The result will always be nil.
+//A non-nil error will be returned to indicate failure.
+//A *data.NotFoundError is returned if the Webhook does not exist.
+//A *data.VersionMismatchError is returned if the Webhook is not at the command's Version.
+func (h *Handler) DeleteWebhook(ctx context.Context, cmd cbus.Command) (interface{}, error) {
+	deleteWebhook := cmd.(*DeleteWebhookCommand)
+
+	return nil, h.Webhooks.Remove(ctx, deleteWebhook.Id, deleteWebhook.Version)
+}
+
+//DeleteWebhookCommand is a Command to delete (remove) a Webhook.
+type DeleteWebhookCommand struct {
+	//Id is the Id of the Webhook to delete.
+	Id data.Id
+
+	//Version, if not nil, is the Version the Webhook must be at to be deleted.
+	Version *int
+}
+
+//Redeliver attempts to send an existing Delivery's event again by adding a new
+//Delivery with the same Payload to h.Deliveries.
+//Cmd must be of type *RedeliverCommand.
+//The result, if not nil and without error, will be the new *webhook.Delivery.
+//A *data.NotFoundError is returned if the Delivery does not exist or does not
+//belong to the command's Webhook.
+func (h *Handler) Redeliver(ctx context.Context, cmd cbus.Command) (interface{}, error) {
+	redeliver := cmd.(*RedeliverCommand)
+
+	original, err := h.Deliveries.Get(ctx, redeliver.DeliveryId)
+	if err == nil && !original.WebhookId.Equal(redeliver.WebhookId) {
+		err = data.NewNotFoundError(webhook.DeliveryEntityName, "id", redeliver.DeliveryId)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := webhook.NewDelivery(original.WebhookId, original.Event, original.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.Deliveries.Add(ctx, *d)
+
+	return d, err
+}
+
+//RedeliverCommand is a Command to send a Delivery's event to its Webhook again.
+type RedeliverCommand struct {
+	//WebhookId is the Id of the Webhook the Delivery belongs to.
+	WebhookId data.Id
+
+	//DeliveryId is the Id of the Delivery to send again.
+	DeliveryId data.Id
+}
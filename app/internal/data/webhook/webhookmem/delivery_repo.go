@@ -0,0 +1,149 @@
+package webhookmem
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/memrepo"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
+)
+
+var _ webhook.DeliveryRepo = &DeliveryRepo{} //Ensure *DeliveryRepo is a webhook.DeliveryRepo.
+
+//DeliveryRepo is a webhook.DeliveryRepo implementation that stores Deliveries
+//in memory.
+type DeliveryRepo struct {
+	mem *memrepo.Repo
+}
+
+//NewDeliveryRepo returns a new DeliveryRepo that uses repo as its storage.
+func NewDeliveryRepo(repo *memrepo.Repo) *DeliveryRepo {
+	return &DeliveryRepo{
+		mem: repo,
+	}
+}
+
+//Get is the webhook.DeliveryQueryRepo implementation.
+func (r *DeliveryRepo) Get(ctx context.Context, id data.Id) (*webhook.Delivery, error) {
+	var result *webhook.Delivery
+
+	err := r.mem.ReadWorkContext(ctx, func(tx *memrepo.Tx) error {
+		row, ok := tx.Get(TableDeliveries, id)
+		if !ok {
+			return data.NewNotFoundError(webhook.DeliveryEntityName, "id", id)
+		}
+
+		result = deliveryFromRow(row)
+		return nil
+	})
+
+	return result, err
+}
+
+//ListPage is the webhook.DeliveryQueryRepo implementation.
+func (r *DeliveryRepo) ListPage(ctx context.Context, webhookId data.Id, page data.PageRequest) ([]*webhook.Delivery, data.Page, error) {
+	after, err := data.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	deliveries, err := r.list(ctx, func(d *webhook.Delivery) bool {
+		return d.WebhookId.Equal(webhookId)
+	})
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return webhook.DeliveryKey(deliveries[i]) < webhook.DeliveryKey(deliveries[j])
+	})
+
+	total := len(deliveries)
+	start := sort.Search(total, func(i int) bool {
+		return webhook.DeliveryKey(deliveries[i]) > after
+	})
+	deliveries = deliveries[start:]
+	if len(deliveries) > page.Limit+1 {
+		deliveries = deliveries[:page.Limit+1]
+	}
+
+	n, result := page.Paginate(len(deliveries), total, func(i int) string {
+		return webhook.DeliveryKey(deliveries[i])
+	})
+
+	return deliveries[:n], result, nil
+}
+
+//ListDue is the webhook.DeliveryRepo implementation.
+func (r *DeliveryRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]*webhook.Delivery, error) {
+	deliveries, err := r.list(ctx, func(d *webhook.Delivery) bool {
+		return d.Status == webhook.StatusPending && d.NextAttemptAt != nil && !d.NextAttemptAt.After(now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return data.TimeKey(*deliveries[i].NextAttemptAt, deliveries[i].Id) < data.TimeKey(*deliveries[j].NextAttemptAt, deliveries[j].Id)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+//list is a helper method to return all Deliveries for which include returns true.
+func (r *DeliveryRepo) list(ctx context.Context, include func(*webhook.Delivery) bool) ([]*webhook.Delivery, error) {
+	result := []*webhook.Delivery{}
+
+	err := r.mem.ReadWorkContext(ctx, func(tx *memrepo.Tx) error {
+		for _, row := range tx.Rows(TableDeliveries) {
+			d := deliveryFromRow(row)
+			if include(d) {
+				result = append(result, d)
+			}
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+//deliveryFromRow is a helper function to return a new Delivery copied from a
+//stored row.
+func deliveryFromRow(row interface{}) *webhook.Delivery {
+	d := row.(webhook.Delivery)
+	return &d
+}
+
+//Add is the webhook.DeliveryRepo implementation.
+func (r *DeliveryRepo) Add(ctx context.Context, deliveries ...webhook.Delivery) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		for _, d := range deliveries {
+			if _, ok := tx.Get(Table, d.WebhookId); !ok {
+				return data.NewNotFoundError(webhook.EntityName, "id", d.WebhookId)
+			}
+			if _, ok := tx.Get(TableDeliveries, d.Id); ok {
+				return data.NewConflictError(webhook.DeliveryEntityName, "id")
+			}
+
+			tx.Put(TableDeliveries, d.Id, d)
+		}
+		return nil
+	})
+}
+
+//Set is the webhook.DeliveryRepo implementation.
+func (r *DeliveryRepo) Set(ctx context.Context, d webhook.Delivery) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		if _, ok := tx.Get(TableDeliveries, d.Id); !ok {
+			return data.NewNotFoundError(webhook.DeliveryEntityName, "id", d.Id)
+		}
+
+		tx.Put(TableDeliveries, d.Id, d)
+		return nil
+	})
+}
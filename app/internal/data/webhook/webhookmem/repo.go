@@ -0,0 +1,170 @@
+package webhookmem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/memrepo"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
+)
+
+var _ webhook.Repo = &Repo{} //Ensure *Repo is a webhook.Repo.
+
+const (
+	//Table is the memrepo table we store our webhook entities in.
+	Table = "webhooks"
+
+	//TableDeliveries is the memrepo table we store our webhook delivery entities in.
+	TableDeliveries = "webhook_deliveries"
+)
+
+//Repo is a webhook.Repo implementation that stores Webhooks in memory.
+type Repo struct {
+	mem *memrepo.Repo
+}
+
+//New returns a new Repo that uses repo as its storage.
+func New(repo *memrepo.Repo) *Repo {
+	return &Repo{
+		mem: repo,
+	}
+}
+
+//Get is the webhook.QueryRepo implementation.
+func (r *Repo) Get(ctx context.Context, id data.Id) (*webhook.Webhook, error) {
+	var result *webhook.Webhook
+
+	err := r.mem.ReadWorkContext(ctx, func(tx *memrepo.Tx) error {
+		row, ok := tx.Get(Table, id)
+		if !ok {
+			return data.NewNotFoundError(webhook.EntityName, "id", id)
+		}
+
+		result = fromRow(row)
+		return nil
+	})
+
+	return result, err
+}
+
+//ListPage is the webhook.QueryRepo implementation.
+func (r *Repo) ListPage(ctx context.Context, page data.PageRequest) ([]*webhook.Webhook, data.Page, error) {
+	after, err := data.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	webhooks, err := r.list(ctx, func(*webhook.Webhook) bool {
+		return true
+	})
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	total := len(webhooks)
+	start := sort.Search(total, func(i int) bool {
+		return webhook.Key(webhooks[i]) > after
+	})
+	webhooks = webhooks[start:]
+	if len(webhooks) > page.Limit+1 {
+		webhooks = webhooks[:page.Limit+1]
+	}
+
+	n, result := page.Paginate(len(webhooks), total, func(i int) string {
+		return webhook.Key(webhooks[i])
+	})
+
+	return webhooks[:n], result, nil
+}
+
+//ListSubscribed is the webhook.QueryRepo implementation.
+func (r *Repo) ListSubscribed(ctx context.Context, name string) ([]*webhook.Webhook, error) {
+	return r.list(ctx, func(w *webhook.Webhook) bool {
+		return w.Subscribes(name)
+	})
+}
+
+//list is a helper method to return all Webhooks for which include returns true
+//in the order the application expects.
+func (r *Repo) list(ctx context.Context, include func(*webhook.Webhook) bool) ([]*webhook.Webhook, error) {
+	result := []*webhook.Webhook{}
+
+	err := r.mem.ReadWorkContext(ctx, func(tx *memrepo.Tx) error {
+		for _, row := range tx.Rows(Table) {
+			w := fromRow(row)
+			if include(w) {
+				result = append(result, w)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return webhook.Key(result[i]) < webhook.Key(result[j])
+	})
+
+	return result, nil
+}
+
+//fromRow is a helper function to return a new Webhook copied from a stored row.
+func fromRow(row interface{}) *webhook.Webhook {
+	w := row.(webhook.Webhook)
+	w.Events = append([]string(nil), w.Events...)
+	return &w
+}
+
+//Add is the webhook.Repo implementation.
+func (r *Repo) Add(ctx context.Context, w webhook.Webhook) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		if _, ok := tx.Get(Table, w.Id); ok {
+			return data.NewConflictError(webhook.EntityName, "id")
+		}
+
+		tx.Put(Table, w.Id, *fromRow(w))
+		return nil
+	})
+}
+
+//Set is the webhook.Repo implementation.
+func (r *Repo) Set(ctx context.Context, w webhook.Webhook) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		row, ok := tx.Get(Table, w.Id)
+		if !ok {
+			return data.NewNotFoundError(webhook.EntityName, "id", w.Id)
+		}
+		if row.(webhook.Webhook).Version != w.Version {
+			return data.NewVersionMismatchError(webhook.EntityName, w.Id)
+		}
+
+		w.Version++
+		tx.Put(Table, w.Id, *fromRow(w))
+		return nil
+	})
+}
+
+//Remove is the webhook.Repo implementation.
+//The Webhook's Deliveries are removed along with it.
+func (r *Repo) Remove(ctx context.Context, id data.Id, version *int) error {
+	return r.mem.TxWorkContext(ctx, func(tx *memrepo.Tx) error {
+		row, ok := tx.Get(Table, id)
+		if !ok {
+			return data.NewNotFoundError(webhook.EntityName, "id", id)
+		}
+		if version != nil && row.(webhook.Webhook).Version != *version {
+			return data.NewVersionMismatchError(webhook.EntityName, id)
+		}
+
+		tx.Delete(Table, id)
+
+		for _, row := range tx.Rows(TableDeliveries) {
+			if d := row.(webhook.Delivery); d.WebhookId.Equal(id) {
+				tx.Delete(TableDeliveries, d.Id)
+			}
+		}
+		return nil
+	})
+}
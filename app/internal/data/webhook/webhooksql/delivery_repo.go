@@ -0,0 +1,259 @@
+package webhooksql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/sqlrepo"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
+)
+
+var _ webhook.DeliveryRepo = &DeliveryRepo{} //Ensure *DeliveryRepo is a webhook.DeliveryRepo.
+
+//SelectFromDeliveries is our delivery select query without filtering, ordering, etc.
+const SelectFromDeliveries = `SELECT
+	d.id,
+	d.webhook_id,
+	d.event,
+	d.payload,
+	d.status,
+	d.attempts,
+	d.next_attempt_at,
+	d.last_attempt_at,
+	d.response_status,
+	d.last_error,
+	d.created_at,
+	d.updated_at
+	FROM ` + TableDeliveries + ` AS d`
+
+//CountFromDeliveries is our delivery count query without filtering.
+const CountFromDeliveries = `SELECT COUNT(*) FROM ` + TableDeliveries + ` AS d`
+
+//DeliveryRepo is a webhook.DeliveryRepo implementation that uses a SQL database
+//as its storage.
+type DeliveryRepo struct {
+	db *sqlrepo.Repo
+}
+
+//NewDeliveryRepo returns a new DeliveryRepo that uses repo to talk to the database.
+func NewDeliveryRepo(repo *sqlrepo.Repo) *DeliveryRepo {
+	return &DeliveryRepo{
+		db: repo,
+	}
+}
+
+//Get is the webhook.DeliveryQueryRepo implementation.
+func (r *DeliveryRepo) Get(ctx context.Context, id data.Id) (*webhook.Delivery, error) {
+	row := r.db.QueryRowContext(ctx, SelectFromDeliveries+" WHERE d.id = ?", id)
+
+	d, err := scanDelivery(row)
+	if err != nil {
+		return nil, sqlrepo.NoRows(err, data.NewNotFoundError(webhook.DeliveryEntityName, "id", id))
+	}
+
+	return d, nil
+}
+
+//ListPage is the webhook.DeliveryQueryRepo implementation.
+func (r *DeliveryRepo) ListPage(ctx context.Context, webhookId data.Id, page data.PageRequest) ([]*webhook.Delivery, data.Page, error) {
+	after, err := data.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	conditions, args := []string{"d.webhook_id = ?"}, []interface{}{webhookId}
+
+	total := 0
+	err = r.db.QueryRowContext(
+		ctx,
+		CountFromDeliveries+sqlrepo.Where(conditions...),
+		args...,
+	).Scan(&total)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	if after != "" {
+		createdAt, id, err := data.ParseTimeKey(after)
+		if err != nil {
+			return nil, data.Page{}, err
+		}
+
+		conditions = append(conditions, "(d.created_at > ? OR (d.created_at = ? AND d.id > ?))")
+		args = append(args, sqlrepo.UTCTime{createdAt}, sqlrepo.UTCTime{createdAt}, id)
+	}
+	query := SelectFromDeliveries + sqlrepo.Where(conditions...) + " ORDER BY d.created_at ASC, d.id ASC LIMIT ?"
+	args = append(args, page.Limit+1)
+
+	deliveries, err := r.list(ctx, query, args...)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	n, result := page.Paginate(len(deliveries), total, func(i int) string {
+		return webhook.DeliveryKey(deliveries[i])
+	})
+
+	return deliveries[:n], result, nil
+}
+
+//ListDue is the webhook.DeliveryRepo implementation.
+func (r *DeliveryRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]*webhook.Delivery, error) {
+	return r.list(
+		ctx,
+		SelectFromDeliveries+" WHERE d.status = ? AND d.next_attempt_at <= ? ORDER BY d.next_attempt_at ASC, d.id ASC LIMIT ?",
+		webhook.StatusPending,
+		sqlrepo.UTCTime{now},
+		limit,
+	)
+}
+
+//list is a helper method to query for a list of Deliveries independent of the
+//actual query.
+func (r *DeliveryRepo) list(ctx context.Context, query string, args ...interface{}) ([]*webhook.Delivery, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*webhook.Delivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+
+	return result, rows.Err()
+}
+
+//scanDelivery is a helper function to scan a single Delivery from a sql row or rows.
+func scanDelivery(s sqlrepo.Scanner) (*webhook.Delivery, error) {
+	d := &webhook.Delivery{}
+
+	var payload []byte
+	nextAttemptAt, lastAttemptAt := sqlrepo.NullUTCTime{}, sqlrepo.NullUTCTime{}
+	createdAt, updatedAt := sqlrepo.UTCTime{}, sqlrepo.UTCTime{}
+
+	if err := s.Scan(
+		&d.Id,
+		&d.WebhookId,
+		&d.Event,
+		&payload,
+		&d.Status,
+		&d.Attempts,
+		&nextAttemptAt,
+		&lastAttemptAt,
+		&d.ResponseStatus,
+		&d.LastError,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	d.Payload = payload
+	if nextAttemptAt.Valid {
+		d.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if lastAttemptAt.Valid {
+		d.LastAttemptAt = &lastAttemptAt.Time
+	}
+	d.CreatedAt, d.UpdatedAt = createdAt.Time, updatedAt.Time
+
+	return d, nil
+}
+
+//Add is the webhook.DeliveryRepo implementation.
+func (r *DeliveryRepo) Add(ctx context.Context, deliveries ...webhook.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(
+		`INSERT INTO %s (
+			id,
+			webhook_id,
+			event,
+			payload,
+			status,
+			attempts,
+			next_attempt_at,
+			last_attempt_at,
+			response_status,
+			last_error,
+			created_at,
+			updated_at
+		) VALUES %s`,
+		TableDeliveries,
+		sqlrepo.List("(?,?,?,?,?,?,?,?,?,?,?,?)", len(deliveries)),
+	)
+
+	args := make([]interface{}, 0, 12*len(deliveries))
+	for _, d := range deliveries {
+		args = append(args, deliveryArgs(d)...)
+	}
+
+	_, err := r.db.ExecContext(ctx, query, args...)
+
+	return r.db.TranslateError(err, webhook.DeliveryEntityName)
+}
+
+//Set is the webhook.DeliveryRepo implementation.
+func (r *DeliveryRepo) Set(ctx context.Context, d webhook.Delivery) error {
+	args := deliveryArgs(d)
+
+	result, err := r.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			`UPDATE %s SET
+				webhook_id = ?,
+				event = ?,
+				payload = ?,
+				status = ?,
+				attempts = ?,
+				next_attempt_at = ?,
+				last_attempt_at = ?,
+				response_status = ?,
+				last_error = ?,
+				created_at = ?,
+				updated_at = ?
+				WHERE id = ?`,
+			TableDeliveries,
+		),
+		append(args[1:], d.Id)...,
+	)
+
+	return sqlrepo.RequireRowsAffected(result, err, data.NewNotFoundError(webhook.DeliveryEntityName, "id", d.Id))
+}
+
+//deliveryArgs is a helper function to return the arguments for all of d's
+//columns in the order they are inserted.
+func deliveryArgs(d webhook.Delivery) []interface{} {
+	return []interface{}{
+		d.Id,
+		d.WebhookId,
+		d.Event,
+		string(d.Payload),
+		d.Status,
+		d.Attempts,
+		nullTime(d.NextAttemptAt),
+		nullTime(d.LastAttemptAt),
+		d.ResponseStatus,
+		d.LastError,
+		sqlrepo.UTCTime{d.CreatedAt},
+		sqlrepo.UTCTime{d.UpdatedAt},
+	}
+}
+
+//nullTime is a helper function to return t as a sqlrepo.NullUTCTime.
+func nullTime(t *time.Time) sqlrepo.NullUTCTime {
+	if t == nil {
+		return sqlrepo.NullUTCTime{}
+	}
+	return sqlrepo.NullUTCTime{Time: *t, Valid: true}
+}
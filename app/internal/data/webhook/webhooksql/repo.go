@@ -0,0 +1,271 @@
+package webhooksql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/sqlrepo"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
+)
+
+var _ webhook.Repo = &Repo{} //Ensure *Repo is a webhook.Repo.
+
+const (
+	//Table is the table we query from to get our webhook entities.
+	Table = "webhooks"
+
+	//TableDeliveries is the table we query from to get our webhook delivery entities.
+	TableDeliveries = "webhook_deliveries"
+)
+
+//SelectFrom is our select query without filtering, ordering, etc.
+const SelectFrom = `SELECT
+	w.id,
+	w.url,
+	w.events,
+	w.secret,
+	w.enabled,
+	w.created_at,
+	w.updated_at,
+	w.version
+	FROM ` + Table + ` AS w`
+
+//CountFrom is our count query without filtering.
+const CountFrom = `SELECT COUNT(*) FROM ` + Table + ` AS w`
+
+//eventsSeparator separates the event names stored in the events column.
+const eventsSeparator = ","
+
+//Repo is a webhook.Repo implementation that uses a SQL database as its storage.
+type Repo struct {
+	db *sqlrepo.Repo
+}
+
+//New returns a new Repo that uses repo to talk to the database.
+func New(repo *sqlrepo.Repo) *Repo {
+	return &Repo{
+		db: repo,
+	}
+}
+
+//Get is the webhook.QueryRepo implementation.
+func (r *Repo) Get(ctx context.Context, id data.Id) (*webhook.Webhook, error) {
+	row := r.db.QueryRowContext(ctx, SelectFrom+" WHERE w.id = ?", id)
+
+	w, err := scan(row)
+	if err != nil {
+		return nil, sqlrepo.NoRows(err, data.NewNotFoundError(webhook.EntityName, "id", id))
+	}
+
+	return w, nil
+}
+
+//ListPage is the webhook.QueryRepo implementation.
+func (r *Repo) ListPage(ctx context.Context, page data.PageRequest) ([]*webhook.Webhook, data.Page, error) {
+	after, err := data.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	total := 0
+	if err := r.db.QueryRowContext(ctx, CountFrom).Scan(&total); err != nil {
+		return nil, data.Page{}, err
+	}
+
+	conditions, args := []string{}, []interface{}{}
+	if after != "" {
+		createdAt, id, err := data.ParseTimeKey(after)
+		if err != nil {
+			return nil, data.Page{}, err
+		}
+
+		conditions = append(conditions, "(w.created_at > ? OR (w.created_at = ? AND w.id > ?))")
+		args = append(args, sqlrepo.UTCTime{createdAt}, sqlrepo.UTCTime{createdAt}, id)
+	}
+	query := orderQuery(SelectFrom+sqlrepo.Where(conditions...)) + " LIMIT ?"
+	args = append(args, page.Limit+1)
+
+	webhooks, err := r.list(ctx, query, args...)
+	if err != nil {
+		return nil, data.Page{}, err
+	}
+
+	n, result := page.Paginate(len(webhooks), total, func(i int) string {
+		return webhook.Key(webhooks[i])
+	})
+
+	return webhooks[:n], result, nil
+}
+
+//ListSubscribed is the webhook.QueryRepo implementation.
+//Enabled Webhooks are filtered by the database and their Events in memory.
+func (r *Repo) ListSubscribed(ctx context.Context, name string) ([]*webhook.Webhook, error) {
+	enabled, err := r.list(ctx, orderQuery(SelectFrom+" WHERE w.enabled = ?"), true)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*webhook.Webhook{}
+	for _, w := range enabled {
+		if w.Subscribes(name) {
+			result = append(result, w)
+		}
+	}
+
+	return result, nil
+}
+
+//orderQuery is a helper function to take an unordered select query and add
+//ordering to it that the application expects.
+func orderQuery(query string) string {
+	return query + " ORDER BY w.created_at ASC, w.id ASC"
+}
+
+//list is a helper method to query for a list of Webhooks independent of the
+//actual query.
+func (r *Repo) list(ctx context.Context, query string, args ...interface{}) ([]*webhook.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*webhook.Webhook{}
+	for rows.Next() {
+		w, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, w)
+	}
+
+	return result, rows.Err()
+}
+
+//scan is a helper function to scan a single Webhook from a sql row or rows.
+func scan(s sqlrepo.Scanner) (*webhook.Webhook, error) {
+	w := &webhook.Webhook{}
+
+	events := ""
+	createdAt, updatedAt := sqlrepo.UTCTime{}, sqlrepo.UTCTime{}
+
+	if err := s.Scan(
+		&w.Id,
+		&w.URL,
+		&events,
+		&w.Secret,
+		&w.Enabled,
+		&createdAt,
+		&updatedAt,
+		&w.Version,
+	); err != nil {
+		return nil, err
+	}
+
+	if events != "" {
+		w.Events = strings.Split(events, eventsSeparator)
+	}
+	w.CreatedAt, w.UpdatedAt = createdAt.Time, updatedAt.Time
+
+	return w, nil
+}
+
+//Add is the webhook.Repo implementation.
+func (r *Repo) Add(ctx context.Context, w webhook.Webhook) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			`INSERT INTO %s (
+				id,
+				url,
+				events,
+				secret,
+				enabled,
+				created_at,
+				updated_at,
+				version
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			Table,
+		),
+		w.Id,
+		w.URL,
+		strings.Join(w.Events, eventsSeparator),
+		w.Secret,
+		w.Enabled,
+		sqlrepo.UTCTime{w.CreatedAt},
+		sqlrepo.UTCTime{w.UpdatedAt},
+		w.Version,
+	)
+
+	return r.db.TranslateError(err, webhook.EntityName)
+}
+
+//Set is the webhook.Repo implementation.
+func (r *Repo) Set(ctx context.Context, w webhook.Webhook) error {
+	work := func(qec sqlrepo.QueryExecerContext) error {
+		result, err := qec.ExecContext(
+			ctx,
+			fmt.Sprintf(
+				`UPDATE %s SET
+					url = ?,
+					events = ?,
+					secret = ?,
+					enabled = ?,
+					created_at = ?,
+					updated_at = ?,
+					version = version + 1
+					WHERE id = ? AND version = ?`,
+				Table,
+			),
+			w.URL,
+			strings.Join(w.Events, eventsSeparator),
+			w.Secret,
+			w.Enabled,
+			sqlrepo.UTCTime{w.CreatedAt},
+			sqlrepo.UTCTime{w.UpdatedAt},
+			w.Id,
+			w.Version,
+		)
+		return sqlrepo.RequireVersionedUpdate(
+			ctx,
+			qec,
+			result,
+			err,
+			Table,
+			w.Id,
+			data.NewNotFoundError(webhook.EntityName, "id", w.Id),
+			data.NewVersionMismatchError(webhook.EntityName, w.Id),
+		)
+	}
+
+	return r.db.TranslateError(r.db.TxWorkContext(ctx, work), webhook.EntityName)
+}
+
+//Remove is the webhook.Repo implementation.
+func (r *Repo) Remove(ctx context.Context, id data.Id, version *int) error {
+	work := func(qec sqlrepo.QueryExecerContext) error {
+		_, err := qec.ExecContext(
+			ctx,
+			fmt.Sprintf("DELETE FROM %s WHERE webhook_id = ?", TableDeliveries),
+			id,
+		)
+		if err != nil {
+			return err
+		}
+
+		//The Deliveries deleted above are rolled back if the Webhook is not.
+		return sqlrepo.DeleteVersioned(
+			ctx,
+			qec,
+			Table,
+			id,
+			version,
+			data.NewNotFoundError(webhook.EntityName, "id", id),
+			data.NewVersionMismatchError(webhook.EntityName, id),
+		)
+	}
+
+	return r.db.TxWorkContext(ctx, work)
+}
@@ -0,0 +1,229 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+const (
+	//DefaultWorkerInterval is the Interval used by a Worker if none is set.
+	DefaultWorkerInterval = time.Second
+
+	//DefaultWorkerBatchSize is the BatchSize used by a Worker if none is set.
+	DefaultWorkerBatchSize = 100
+
+	//DefaultMaxAttempts is the MaxAttempts used by a Worker if none is set.
+	DefaultMaxAttempts = 8
+
+	//DefaultDeliveryTimeout is the timeout of the http.Client used by a Worker
+	//if none is set.
+	DefaultDeliveryTimeout = 10 * time.Second
+
+	//maxErrorBody is the number of bytes of a failed response's body kept in a
+	//Delivery's LastError.
+	maxErrorBody = 512
+)
+
+//DefaultBackoff is the Backoff used by a Worker if none is set.
+//It waits 10 seconds after the first attempt and doubles up to an hour.
+var DefaultBackoff = ExponentialBackoff(10*time.Second, time.Hour)
+
+//ExponentialBackoff returns a backoff function that waits base after the first
+//attempt and twice as long after each following attempt, up to max.
+func ExponentialBackoff(base, max time.Duration) func(attempts int) time.Duration {
+	return func(attempts int) time.Duration {
+		wait := base
+		for i := 1; i < attempts && wait < max; i++ {
+			wait *= 2
+		}
+		if wait > max {
+			wait = max
+		}
+		return wait
+	}
+}
+
+//Worker attempts due Deliveries by POSTing them to their Webhooks and retries
+//failed attempts with backoff.
+type Worker struct {
+	//Webhooks is where Deliveries' Webhooks are retrieved from.
+	Webhooks QueryRepo
+
+	//Deliveries is where due Deliveries are retrieved from and updated.
+	Deliveries DeliveryRepo
+
+	//Client sends the requests.
+	//If nil, a client with DefaultDeliveryTimeout is used.
+	Client *http.Client
+
+	//Interval is how often due Deliveries are checked for.
+	Interval time.Duration
+
+	//BatchSize is the maximum number of Deliveries attempted at once.
+	BatchSize int
+
+	//MaxAttempts is the number of attempts after which a Delivery fails.
+	MaxAttempts int
+
+	//Backoff returns how long to wait after a Delivery's failed attempts before
+	//attempting again.
+	Backoff func(attempts int) time.Duration
+
+	//OnError, if not nil, is called with errors that occur while working.
+	//Failed attempts are recorded on their Delivery instead.
+	OnError func(err error)
+}
+
+//Run attempts due Deliveries every w.Interval until ctx is done.
+//It always returns ctx.Err().
+func (w *Worker) Run(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWorkerInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.DeliverDue(ctx); err != nil && ctx.Err() == nil && w.OnError != nil {
+			w.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+//DeliverDue attempts a single batch of due Deliveries and records the results.
+//It returns the number of Deliveries attempted.
+func (w *Worker) DeliverDue(ctx context.Context) (int, error) {
+	batchSize := w.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultWorkerBatchSize
+	}
+
+	deliveries, err := w.Deliveries.ListDue(ctx, time.Now().UTC(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, d := range deliveries {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+		if err := w.Deliver(ctx, d); err != nil {
+			return i, err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+//Deliver makes a single attempt of d and records the result with w.Deliveries.
+//The error returned is only from retrieving d's Webhook or recording the result.
+func (w *Worker) Deliver(ctx context.Context, d *Delivery) error {
+	webhook, err := w.Webhooks.Get(ctx, d.WebhookId)
+	if err != nil && !data.IsNotFound(err) {
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	d.Attempts++
+	d.LastAttemptAt = &now
+	d.UpdatedAt = now
+
+	//final is whether or not the attempt should not be retried regardless of
+	//how many attempts are left.
+	final := true
+
+	switch {
+	case webhook == nil:
+		d.ResponseStatus, d.LastError = 0, "webhook no longer exists"
+	case !webhook.Enabled:
+		d.ResponseStatus, d.LastError = 0, "webhook is disabled"
+	default:
+		final = false
+		d.ResponseStatus, err = w.send(ctx, webhook, d, now)
+		d.LastError = ""
+		if err != nil {
+			d.LastError = err.Error()
+		}
+	}
+
+	switch {
+	case d.LastError == "":
+		d.Status, d.NextAttemptAt = StatusSucceeded, nil
+	case final, d.Attempts >= w.maxAttempts():
+		d.Status, d.NextAttemptAt = StatusFailed, nil
+	default:
+		next := now.Add(w.backoff(d.Attempts))
+		d.Status, d.NextAttemptAt = StatusPending, &next
+	}
+
+	return w.Deliveries.Set(ctx, *d)
+}
+
+//send POSTs d's Payload to webhook and returns the response's status.
+//A non-nil error is returned if there was no response or its status is not 2xx.
+func (w *Worker) send(ctx context.Context, webhook *Webhook, d *Delivery, now time.Time) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderId, webhook.Id.String())
+	req.Header.Set(HeaderDelivery, d.Id.String())
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, d.Payload))
+
+	resp, err := w.client().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook: response status %d: %s", resp.StatusCode, body)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (w *Worker) client() *http.Client {
+	if w.Client == nil {
+		return &http.Client{Timeout: DefaultDeliveryTimeout}
+	}
+	return w.Client
+}
+
+func (w *Worker) maxAttempts() int {
+	if w.MaxAttempts <= 0 {
+		return DefaultMaxAttempts
+	}
+	return w.MaxAttempts
+}
+
+func (w *Worker) backoff(attempts int) time.Duration {
+	if w.Backoff == nil {
+		return DefaultBackoff(attempts)
+	}
+	return w.Backoff(attempts)
+}
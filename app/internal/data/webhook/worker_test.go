@@ -0,0 +1,359 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/memrepo"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook/webhookcmd"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook/webhookmem"
+)
+
+const testSecret = "secret"
+
+//testEvent is the event.Event dispatched in tests.
+type testEvent struct {
+	Name string
+}
+
+func (e testEvent) EventName() string {
+	return "test.happened"
+}
+
+//request is a request received by a receiver.
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+//receiver is an httptest.Server that records the requests it receives and
+//responds with its statuses in order, and then with http.StatusOK.
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []request
+}
+
+func newReceiver(statuses ...int) *receiver {
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.requests = append(r.requests, request{header: req.Header, body: body})
+
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	return r
+}
+
+func (r *receiver) received() []request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]request(nil), r.requests...)
+}
+
+//fixture is the repositories and handlers used by a test.
+type fixture struct {
+	handler    *webhookcmd.Handler
+	dispatcher *webhook.Dispatcher
+	worker     *webhook.Worker
+	webhook    *webhook.Webhook
+}
+
+func newFixture(t *testing.T, url string) *fixture {
+	mem := memrepo.New()
+	webhooks, deliveries := webhookmem.New(mem), webhookmem.NewDeliveryRepo(mem)
+
+	f := &fixture{
+		handler: &webhookcmd.Handler{
+			Webhooks:   webhooks,
+			Deliveries: deliveries,
+		},
+		dispatcher: &webhook.Dispatcher{
+			Webhooks:   webhooks,
+			Deliveries: deliveries,
+			Data: func(e event.Event) (interface{}, error) {
+				return map[string]string{"name": e.(testEvent).Name}, nil
+			},
+		},
+		worker: &webhook.Worker{
+			Webhooks:   webhooks,
+			Deliveries: deliveries,
+			Backoff: func(attempts int) time.Duration {
+				return 0
+			},
+		},
+	}
+
+	result, err := f.handler.CreateWebhook(context.Background(), &webhookcmd.CreateWebhookCommand{
+		URL:     url,
+		Secret:  testSecret,
+		Enabled: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.webhook = result.(*webhook.Webhook)
+
+	return f
+}
+
+//dispatch dispatches a testEvent published with id and returns its Delivery.
+func (f *fixture) dispatch(t *testing.T, id data.Id) *webhook.Delivery {
+	ctx := event.ContextWithId(context.Background(), id)
+	if err := f.dispatcher.HandleEvent(ctx, testEvent{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries, _, err := f.handler.Deliveries.ListPage(context.Background(), f.webhook.Id, data.PageRequest{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("len(deliveries) = %d, want 1", len(deliveries))
+	}
+	return deliveries[0]
+}
+
+//deliverDue runs f.worker once and returns the Delivery with id afterwards.
+func (f *fixture) deliverDue(t *testing.T, id data.Id) *webhook.Delivery {
+	if _, err := f.worker.DeliverDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := f.handler.Deliveries.Get(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func newId(t *testing.T) data.Id {
+	id, err := data.NewId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestWorker_signsDeliveries(t *testing.T) {
+	r := newReceiver()
+	defer r.Close()
+
+	f := newFixture(t, r.URL)
+	eventId := newId(t)
+	d := f.deliverDue(t, f.dispatch(t, eventId).Id)
+
+	if d.Status != webhook.StatusSucceeded || d.Attempts != 1 || d.ResponseStatus != http.StatusOK {
+		t.Fatalf("delivery = %+v, want succeeded after 1 attempt", d)
+	}
+
+	requests := r.received()
+	if len(requests) != 1 {
+		t.Fatalf("len(requests) = %d, want 1", len(requests))
+	}
+	req := requests[0]
+
+	timestamp, signature := req.header.Get(webhook.HeaderTimestamp), req.header.Get(webhook.HeaderSignature)
+	if !webhook.Verify(testSecret, timestamp, req.body, signature) {
+		t.Errorf("signature %q does not verify body %s at %q", signature, req.body, timestamp)
+	}
+	if webhook.Verify("other", timestamp, req.body, signature) {
+		t.Errorf("signature %q verifies with the wrong secret", signature)
+	}
+	if webhook.Verify(testSecret, timestamp, append(req.body, ' '), signature) {
+		t.Errorf("signature %q verifies a changed body", signature)
+	}
+
+	if got := req.header.Get(webhook.HeaderDelivery); got != d.Id.String() {
+		t.Errorf("%s = %q, want %q", webhook.HeaderDelivery, got, d.Id)
+	}
+	if got := req.header.Get(webhook.HeaderEvent); got != "test.happened" {
+		t.Errorf("%s = %q, want %q", webhook.HeaderEvent, got, "test.happened")
+	}
+
+	envelope := struct {
+		Id    data.Id           `json:"id"`
+		Event string            `json:"event"`
+		Data  map[string]string `json:"data"`
+	}{}
+	if err := json.Unmarshal(req.body, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	if !envelope.Id.Equal(eventId) || envelope.Event != "test.happened" || envelope.Data["name"] != "a" {
+		t.Errorf("envelope = %+v, want the event's id and data", envelope)
+	}
+}
+
+func TestWorker_retriesFailedAttempts(t *testing.T) {
+	r := newReceiver(http.StatusInternalServerError, http.StatusBadGateway)
+	defer r.Close()
+
+	f := newFixture(t, r.URL)
+
+	var waited []int
+	f.worker.Backoff = func(attempts int) time.Duration {
+		waited = append(waited, attempts)
+		return 0
+	}
+
+	id := f.dispatch(t, newId(t)).Id
+
+	d := f.deliverDue(t, id)
+	if d.Status != webhook.StatusPending || d.ResponseStatus != http.StatusInternalServerError || d.LastError == "" {
+		t.Fatalf("delivery = %+v, want pending after a 500", d)
+	}
+	if d.NextAttemptAt == nil || !d.NextAttemptAt.Equal(*d.LastAttemptAt) {
+		t.Fatalf("NextAttemptAt = %v, want LastAttemptAt plus the backoff", d.NextAttemptAt)
+	}
+
+	d = f.deliverDue(t, id)
+	if d.Status != webhook.StatusPending || d.ResponseStatus != http.StatusBadGateway {
+		t.Fatalf("delivery = %+v, want pending after a 502", d)
+	}
+
+	d = f.deliverDue(t, id)
+	if d.Status != webhook.StatusSucceeded || d.Attempts != 3 || d.LastError != "" || d.NextAttemptAt != nil {
+		t.Fatalf("delivery = %+v, want succeeded after 3 attempts", d)
+	}
+
+	if len(waited) != 2 || waited[0] != 1 || waited[1] != 2 {
+		t.Errorf("backoff called with %v, want [1 2]", waited)
+	}
+	if n := len(r.received()); n != 3 {
+		t.Errorf("received %d requests, want 3", n)
+	}
+}
+
+func TestWorker_failsAfterMaxAttempts(t *testing.T) {
+	r := newReceiver(http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	defer r.Close()
+
+	f := newFixture(t, r.URL)
+	f.worker.MaxAttempts = 2
+
+	id := f.dispatch(t, newId(t)).Id
+	f.deliverDue(t, id)
+	d := f.deliverDue(t, id)
+
+	if d.Status != webhook.StatusFailed || d.Attempts != 2 || d.NextAttemptAt != nil {
+		t.Fatalf("delivery = %+v, want failed after 2 attempts", d)
+	}
+
+	if n, err := f.worker.DeliverDue(context.Background()); err != nil || n != 0 {
+		t.Errorf("DeliverDue() = %d, %v, want nothing due", n, err)
+	}
+	if n := len(r.received()); n != 2 {
+		t.Errorf("received %d requests, want 2", n)
+	}
+}
+
+func TestWorker_waitsForBackoff(t *testing.T) {
+	r := newReceiver(http.StatusInternalServerError)
+	defer r.Close()
+
+	f := newFixture(t, r.URL)
+	f.worker.Backoff = func(attempts int) time.Duration {
+		return time.Hour
+	}
+
+	id := f.dispatch(t, newId(t)).Id
+	d := f.deliverDue(t, id)
+
+	if d.NextAttemptAt == nil || !d.NextAttemptAt.Equal(d.LastAttemptAt.Add(time.Hour)) {
+		t.Fatalf("NextAttemptAt = %v, want an hour after %v", d.NextAttemptAt, d.LastAttemptAt)
+	}
+	if n, err := f.worker.DeliverDue(context.Background()); err != nil || n != 0 {
+		t.Errorf("DeliverDue() = %d, %v, want nothing due", n, err)
+	}
+}
+
+func TestRedeliver(t *testing.T) {
+	r := newReceiver()
+	defer r.Close()
+
+	f := newFixture(t, r.URL)
+	original := f.deliverDue(t, f.dispatch(t, newId(t)).Id)
+
+	result, err := f.handler.Redeliver(context.Background(), &webhookcmd.RedeliverCommand{
+		WebhookId:  f.webhook.Id,
+		DeliveryId: original.Id,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	redelivery := f.deliverDue(t, result.(*webhook.Delivery).Id)
+
+	if redelivery.Id.Equal(original.Id) || redelivery.Status != webhook.StatusSucceeded {
+		t.Fatalf("redelivery = %+v, want a new succeeded Delivery", redelivery)
+	}
+
+	requests := r.received()
+	if len(requests) != 2 {
+		t.Fatalf("len(requests) = %d, want 2", len(requests))
+	}
+	if string(requests[0].body) != string(requests[1].body) {
+		t.Errorf("redelivered body %s, want %s", requests[1].body, requests[0].body)
+	}
+	if requests[0].header.Get(webhook.HeaderDelivery) == requests[1].header.Get(webhook.HeaderDelivery) {
+		t.Errorf("redelivery has the same %s as the original", webhook.HeaderDelivery)
+	}
+	if !webhook.Verify(testSecret, requests[1].header.Get(webhook.HeaderTimestamp), requests[1].body, requests[1].header.Get(webhook.HeaderSignature)) {
+		t.Errorf("redelivery signature does not verify")
+	}
+}
+
+func TestRedeliver_otherWebhook(t *testing.T) {
+	r := newReceiver()
+	defer r.Close()
+
+	f := newFixture(t, r.URL)
+	original := f.dispatch(t, newId(t))
+
+	_, err := f.handler.Redeliver(context.Background(), &webhookcmd.RedeliverCommand{
+		WebhookId:  newId(t),
+		DeliveryId: original.Id,
+	})
+	if !data.IsNotFound(err) {
+		t.Fatalf("err = %v, want a not found error", err)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := webhook.ExponentialBackoff(10*time.Second, time.Minute)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{4, time.Minute},
+		{20, time.Minute},
+	}
+	for _, test := range tests {
+		if got := backoff(test.attempts); got != test.want {
+			t.Errorf("backoff(%d) = %v, want %v", test.attempts, got, test.want)
+		}
+	}
+}
@@ -1,16 +1,51 @@
 package gfsweb
 
 import (
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event/eventstream"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api"
 	"github.com/gogolfing/cbus"
+	"github.com/gogolfing/config"
 )
 
-//CreateAPI returns a new api.API populated with bus and the query repositories
-//in repos.
-func CreateAPI(bus *cbus.Bus, repos *Repos) *api.API {
+const (
+	//ConfigKeyEnvironment is the config key used to determine the environment the
+	//application is running in.
+	ConfigKeyEnvironment = "environment"
+
+	//EnvironmentDevelopment is the environment in which internal error details
+	//are sent in API responses.
+	//Every other environment, including none, is treated as production.
+	EnvironmentDevelopment = "development"
+
+	//ConfigKeyAuthDisabled is the config key used to serve API requests without
+	//an api key.
+	//Authentication is only disabled if its value is "true".
+	ConfigKeyAuthDisabled = "auth.disabled"
+)
+
+//CreateAPI returns a new api.API populated with bus, the transactor and query
+//repositories in repos, and stream.
+//Internal error details are only exposed if the environment in config is
+//EnvironmentDevelopment, and requests must be authenticated by an api key unless
+//disabled in config at key ConfigKeyAuthDisabled.
+func CreateAPI(config *config.Config, bus *cbus.Bus, repos *Repos, stream *eventstream.Stream) *api.API {
 	return &api.API{
-		Bus:     bus,
+		Bus:        bus,
+		Transactor: repos.Transactor,
+
 		Users:   repos.Users,
 		Clients: repos.Clients,
+		Audit:   repos.Audit,
+		Changes: repos.Changes,
+
+		Webhooks:          repos.Webhooks,
+		WebhookDeliveries: repos.WebhookDeliveries,
+
+		APIKeys: repos.APIKeys,
+
+		Stream: stream,
+
+		ExposeInternalErrors: config.GetString(ConfigKeyEnvironment) == EnvironmentDevelopment,
+		AuthDisabled:         config.GetString(ConfigKeyAuthDisabled) == "true",
 	}
 }
@@ -4,10 +4,17 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"sync"
 
 	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/server"
 )
 
+//Worker is a background process that runs alongside the App's server.
+type Worker interface {
+	//Run should work until ctx is done.
+	Run(ctx context.Context) error
+}
+
 //App is the central application type for the gfsweb executable.
 type App struct {
 	//server is our application http server.
@@ -16,6 +23,10 @@ type App struct {
 	//handler is used to handle all incoming requests.
 	handler http.Handler
 
+	//workers run in the background while the server is serving, e.g. to deliver
+	//published events to the application's subscribers.
+	workers []Worker
+
 	//dbCloser is a Closer that should be called to close our connection to the database.
 	dbCloser io.Closer
 }
@@ -24,7 +35,24 @@ type App struct {
 //It calls Serce on a's internal server.Server.
 //It also waits in a sepearate goroutine for ctx to be done and calls Shutdown
 //on the internal server.
+//
+//Each of a's workers runs in its own goroutine until Run returns.
 func (a *App) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	wg := &sync.WaitGroup{}
+	for _, worker := range a.workers {
+		wg.Add(1)
+		go func(worker Worker) {
+			defer wg.Done()
+			worker.Run(ctx)
+		}(worker)
+	}
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
 	go func() {
 		defer a.server.Shutdown(context.Background())
 		<-ctx.Done()
@@ -1,25 +1,66 @@
 package gfsweb
 
 import (
+	"fmt"
 	"io"
 
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event/eventstream"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/outbox"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/memrepo"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/sqlrepo"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/server"
 	"github.com/gogolfing/cbus"
 	"github.com/gogolfing/config"
 )
 
+const (
+	//ConfigKeyStorageDriver is the config key used to select where the application
+	//stores its domain entities.
+	ConfigKeyStorageDriver = "storage.driver"
+
+	//StorageDriverSQL stores domain entities in a SQL database.
+	//It is the default if no storage driver is configured.
+	StorageDriverSQL = "sql"
+
+	//StorageDriverMemory stores domain entities in memory.
+	//Nothing is persisted after the application exits.
+	StorageDriverMemory = "memory"
+)
+
 //SQLRepoFactory is a function type that creates a new sqlrepo.Reop and a related
 //io.Closer that should close the connection to the database.
 type SQLRepoFactory func(config *config.Config) (*sqlrepo.Repo, io.Closer, error)
 
+//MemRepoFactory is a function type that creates a new memrepo.Repo and a related
+//io.Closer that should be called when the repo is no longer needed.
+type MemRepoFactory func(config *config.Config) (*memrepo.Repo, io.Closer, error)
+
+//EventBusFactory is a function type that creates a new event.Bus with all
+//Subscribers that should receive the application's events registered.
+type EventBusFactory func(repos *Repos) *event.Bus
+
 //CBusFactory is a function type that creates a new cbus.Bus will all required
 //Commands and Handlers correctly registered.
-type CBusFactory func(repos *Repos) *cbus.Bus
+//Handlers should publish their events to events, which stores them in the outbox.
+type CBusFactory func(repos *Repos, events event.Publisher) *cbus.Bus
+
+//RelayFactory is a function type that creates a new outbox.Relay that delivers
+//the events in the outbox in repos to events.
+type RelayFactory func(repos *Repos, events event.Publisher) *outbox.Relay
+
+//WebhookWorkerFactory is a function type that creates a new webhook.Worker that
+//attempts the webhook Deliveries in repos.
+type WebhookWorkerFactory func(repos *Repos) *webhook.Worker
+
+//EventStreamFactory is a function type that creates a new eventstream.Stream that
+//receives the application's events and streams them to API clients.
+type EventStreamFactory func() *eventstream.Stream
 
 //APIFactory is a function type that creates a new API ready for use.
-type APIFactory func(bus *cbus.Bus, repos *Repos) *api.API
+type APIFactory func(config *config.Config, bus *cbus.Bus, repos *Repos, stream *eventstream.Stream) *api.API
 
 //ServerFactory is a function type that creates a new server.Server ready for use.
 type ServerFactory func(config *config.Config) *server.Server
@@ -29,8 +70,18 @@ type ServerFactory func(config *config.Config) *server.Server
 type AppBuilder struct {
 	SQLRepoFactory
 
+	MemRepoFactory
+
+	EventBusFactory
+
 	CBusFactory
 
+	RelayFactory
+
+	WebhookWorkerFactory
+
+	EventStreamFactory
+
 	APIFactory
 
 	ServerFactory
@@ -39,17 +90,22 @@ type AppBuilder struct {
 //NewAppBuilder returns a new AppBuilder with fields set to default values.
 func NewAppBuilder() *AppBuilder {
 	return &AppBuilder{
-		SQLRepoFactory: CreateSQLRepo,
-		CBusFactory:    CreateCBus,
-		APIFactory:     CreateAPI,
-		ServerFactory:  CreateServer,
+		SQLRepoFactory:       CreateSQLRepo,
+		MemRepoFactory:       CreateMemRepo,
+		EventBusFactory:      CreateEventBus,
+		CBusFactory:          CreateCBus,
+		RelayFactory:         CreateRelay,
+		WebhookWorkerFactory: CreateWebhookWorker,
+		EventStreamFactory:   CreateEventStream,
+		APIFactory:           CreateAPI,
+		ServerFactory:        CreateServer,
 	}
 }
 
 //Build uses config and its factory functions to build a new App.
 //If err is nil, the returned App is ready to Run.
 func (ab *AppBuilder) Build(config *config.Config) (app *App, err error) {
-	var sqlRepo *sqlrepo.Repo
+	var repos *Repos
 	var dbCloser io.Closer
 
 	defer func() {
@@ -58,22 +114,69 @@ func (ab *AppBuilder) Build(config *config.Config) (app *App, err error) {
 		}
 	}()
 
-	sqlRepo, dbCloser, err = ab.SQLRepoFactory(config)
+	repos, dbCloser, err = ab.createRepos(config)
 	if err != nil {
 		return nil, err
 	}
 
-	repos := NewRepos(sqlRepo)
+	events := ab.EventBusFactory(repos)
+
+	stream := ab.EventStreamFactory()
+	events.Subscribe(event.All, stream)
 
-	bus := ab.CBusFactory(repos)
+	bus := ab.CBusFactory(repos, &outbox.Publisher{Messages: repos.Outbox})
 
-	api := ab.APIFactory(bus, repos)
+	workers := []Worker{
+		ab.RelayFactory(repos, events),
+		ab.WebhookWorkerFactory(repos),
+		stream,
+	}
+
+	api := ab.APIFactory(config, bus, repos, stream)
 
 	server := ab.ServerFactory(config)
 
 	return &App{
 		server:   server,
 		handler:  api.Handler(),
+		workers:  workers,
 		dbCloser: dbCloser,
 	}, nil
 }
+
+//BuildBus uses config and its factory functions to build only the cbus.Bus of
+//an App, which executes commands without running the App.
+//The returned io.Closer should be closed when the bus is no longer needed.
+func (ab *AppBuilder) BuildBus(config *config.Config) (*cbus.Bus, io.Closer, error) {
+	repos, dbCloser, err := ab.createRepos(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bus := ab.CBusFactory(repos, &outbox.Publisher{Messages: repos.Outbox})
+
+	return bus, dbCloser, nil
+}
+
+//createRepos creates the Repos for the storage driver specified in config at
+//key ConfigKeyStorageDriver.
+func (ab *AppBuilder) createRepos(config *config.Config) (*Repos, io.Closer, error) {
+	switch driver := config.GetString(ConfigKeyStorageDriver); driver {
+	case "", StorageDriverSQL:
+		sqlRepo, dbCloser, err := ab.SQLRepoFactory(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		return NewRepos(sqlRepo), dbCloser, nil
+
+	case StorageDriverMemory:
+		memRepo, memCloser, err := ab.MemRepoFactory(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		return NewMemRepos(memRepo), memCloser, nil
+
+	default:
+		return nil, nil, fmt.Errorf("gfsweb: unknown storage driver %q", driver)
+	}
+}
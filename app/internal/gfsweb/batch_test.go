@@ -0,0 +1,191 @@
+package gfsweb_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/memrepo"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api"
+	"github.com/gogolfing/config"
+)
+
+//failEmailPrefix is the Email prefix of created Users whose user.UserCreated
+//event fails to publish, so that their commands fail after the User was added.
+const failEmailPrefix = "fail"
+
+//batchResponse is the decoded body of a batch response.
+type batchResponse struct {
+	Index   *int `json:"index"`
+	Results []struct {
+		Index  int             `json:"index"`
+		Status int             `json:"status"`
+		Data   json.RawMessage `json:"data"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"results"`
+}
+
+func TestBatch_atomic(t *testing.T) {
+	h, repos := newBatchAPI(t)
+
+	w := postBatch(t, h, "atomic",
+		`{"op": "create", "data": {"email": "a@example.com"}}`,
+		`{"op": "create", "data": {"email": "b@example.com"}}`,
+		`{"op": "create", "data": {"email": "fail@example.com"}}`,
+		`{"op": "create", "data": {"email": "c@example.com"}}`,
+	)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusInternalServerError, w.Body)
+	}
+	resp := decodeBatch(t, w)
+	if resp.Index == nil || *resp.Index != 2 {
+		t.Errorf("index = %v, want 2", resp.Index)
+	}
+
+	requireEmails(t, repos)
+}
+
+func TestBatch_atomicInvalid(t *testing.T) {
+	h, repos := newBatchAPI(t)
+
+	w := postBatch(t, h, "",
+		`{"op": "create", "data": {"email": "a@example.com"}}`,
+		`{"op": "create", "data": {"email": "invalid"}}`,
+	)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusUnprocessableEntity, w.Body)
+	}
+	resp := decodeBatch(t, w)
+	if resp.Index == nil || *resp.Index != 1 {
+		t.Errorf("index = %v, want 1", resp.Index)
+	}
+
+	requireEmails(t, repos)
+}
+
+func TestBatch_partial(t *testing.T) {
+	h, repos := newBatchAPI(t)
+
+	w := postBatch(t, h, "partial",
+		`{"op": "create", "data": {"email": "a@example.com"}}`,
+		`{"op": "create", "data": {"email": "fail@example.com"}}`,
+		`{"op": "create", "data": {"email": "a@example.com"}}`,
+		`{"op": "create", "data": {"email": "invalid"}}`,
+		`{"op": "create", "data": {"email": "b@example.com"}}`,
+	)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	resp := decodeBatch(t, w)
+
+	want := []int{
+		http.StatusCreated,
+		http.StatusInternalServerError,
+		http.StatusConflict,
+		http.StatusUnprocessableEntity,
+		http.StatusCreated,
+	}
+	if len(resp.Results) != len(want) {
+		t.Fatalf("len(results) = %d, want %d", len(resp.Results), len(want))
+	}
+	for i, result := range resp.Results {
+		if result.Index != i || result.Status != want[i] {
+			t.Errorf("results[%d] = index %d, status %d, want index %d, status %d", i, result.Index, result.Status, i, want[i])
+		}
+		if succeeded := want[i] == http.StatusCreated; succeeded != (len(result.Data) > 0) || succeeded == (len(result.Error) > 0) {
+			t.Errorf("results[%d] data = %s, error = %s", i, result.Data, result.Error)
+		}
+	}
+
+	requireEmails(t, repos, "a@example.com", "b@example.com")
+}
+
+func TestBatch_tooLarge(t *testing.T) {
+	h, repos := newBatchAPI(t)
+
+	ops := make([]string, api.MaxBatchOperations+1)
+	for i := range ops {
+		ops[i] = fmt.Sprintf(`{"op": "create", "data": {"email": "%d@example.com"}}`, i)
+	}
+
+	for _, mode := range []string{"atomic", "partial"} {
+		if w := postBatch(t, h, mode, ops...); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d: %s", mode, w.Code, http.StatusBadRequest, w.Body)
+		}
+	}
+
+	requireEmails(t, repos)
+}
+
+//newBatchAPI returns a handler for an api.API that stores entities with the
+//memory storage driver and the Repos it uses.
+//The commands of created Users with failEmailPrefix fail after adding them.
+func newBatchAPI(t *testing.T) (http.Handler, *gfsweb.Repos) {
+	repos := gfsweb.NewMemRepos(memrepo.New())
+
+	events := &event.Bus{}
+	events.Subscribe(user.EventCreated, event.SubscriberFunc(func(ctx context.Context, e event.Event) error {
+		if strings.HasPrefix(e.(user.UserCreated).User.Email, failEmailPrefix) {
+			return errors.New("failed to publish")
+		}
+		return nil
+	}))
+
+	a := gfsweb.CreateAPI(config.New(), gfsweb.CreateCBus(repos, events), repos, gfsweb.CreateEventStream())
+	a.AuthDisabled = true
+
+	return a.Handler(), repos
+}
+
+//postBatch sends a users batch request with mode and ops to h.
+func postBatch(t *testing.T, h http.Handler, mode string, ops ...string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body := fmt.Sprintf(`{"mode": %q, "operations": [%s]}`, mode, strings.Join(ops, ","))
+	r := httptest.NewRequest(http.MethodPost, "/users:batch", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+//decodeBatch decodes the batch response body in w.
+func decodeBatch(t *testing.T, w *httptest.ResponseRecorder) *batchResponse {
+	t.Helper()
+
+	resp := &batchResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+//requireEmails fails t if the stored Users do not have exactly emails.
+func requireEmails(t *testing.T, repos *gfsweb.Repos, emails ...string) {
+	t.Helper()
+
+	users, err := repos.Users.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]string, len(users))
+	for i, u := range users {
+		got[i] = u.Email
+	}
+	sort.Strings(got)
+
+	if fmt.Sprint(got) != fmt.Sprint(emails) || len(got) != len(emails) {
+		t.Fatalf("emails = %v, want %v", got, emails)
+	}
+}
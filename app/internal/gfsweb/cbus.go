@@ -1,38 +1,231 @@
 package gfsweb
 
 import (
+	"context"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey/apikeycmd"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/audit"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client/clientcmd"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user/usercmd"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook/webhookcmd"
 	"github.com/gogolfing/cbus"
 )
 
-//CreateCBus returns a new cbus.Bus with user and client commands registered on it.
-func CreateCBus(repos *Repos) *cbus.Bus {
+//HandlerWrapper is a function type that decorates the cbus.Handler for commands
+//that operate on target's entities.
+type HandlerWrapper func(target audit.Target, next cbus.Handler) cbus.Handler
+
+//CreateCBus returns a new cbus.Bus with user, client, webhook, and api key
+//commands registered on it.
+//
+//Every command executed through the bus runs inside of a single transaction
+//from repos.Transactor, in which it is recorded in repos.Audit and its handler
+//publishes its events to events.
+func CreateCBus(repos *Repos, events event.Publisher) *cbus.Bus {
 	bus := &cbus.Bus{}
 
-	RegisterUserCommands(bus, repos.Users)
-	RegisterClientCommands(bus, repos.Clients)
+	recorder := &audit.Recorder{
+		Entries: repos.Audit,
+	}
+
+	wrap := func(target audit.Target, next cbus.Handler) cbus.Handler {
+		return Transactional(repos.Transactor, recorder.Handler(target, next))
+	}
+
+	RegisterUserCommands(bus, repos.Users, repos.Clients, events, wrap)
+	RegisterClientCommands(bus, repos.Clients, repos.Users, events, wrap)
+	RegisterWebhookCommands(bus, repos.Webhooks, repos.WebhookDeliveries, wrap)
+	RegisterAPIKeyCommands(bus, repos.APIKeys, wrap)
 
 	return bus
 }
 
-func RegisterUserCommands(bus *cbus.Bus, users user.Repo) {
+func RegisterUserCommands(bus *cbus.Bus, users user.Repo, clients client.QueryRepo, events event.Publisher, wrap HandlerWrapper) {
 	h := &usercmd.Handler{
-		Users: users,
+		Users:   users,
+		Clients: clients,
+		Events:  events,
 	}
 
-	bus.Handle(&usercmd.CreateUserCommand{}, cbus.HandlerFunc(h.CreateUser))
-	bus.Handle(&usercmd.UpdateUserCommand{}, cbus.HandlerFunc(h.UpdateUser))
+	target := UserAuditTarget(users)
+
+	bus.Handle(&usercmd.CreateUserCommand{}, wrap(target, cbus.HandlerFunc(h.CreateUser)))
+	bus.Handle(&usercmd.UpdateUserCommand{}, wrap(target, cbus.HandlerFunc(h.UpdateUser)))
+	bus.Handle(&usercmd.DeleteUserCommand{}, wrap(target, cbus.HandlerFunc(h.DeleteUser)))
+	bus.Handle(&usercmd.AddUserClientCommand{}, wrap(target, cbus.HandlerFunc(h.AddUserClient)))
+	bus.Handle(&usercmd.RemoveUserClientCommand{}, wrap(target, cbus.HandlerFunc(h.RemoveUserClient)))
 }
 
-func RegisterClientCommands(bus *cbus.Bus, clients client.Repo) {
+func RegisterClientCommands(bus *cbus.Bus, clients client.Repo, users user.Repo, events event.Publisher, wrap HandlerWrapper) {
 	h := &clientcmd.Handler{
 		Clients: clients,
+		Users:   users,
+		Events:  events,
+	}
+
+	target := ClientAuditTarget(clients)
+
+	bus.Handle(&clientcmd.CreateClientCommand{}, wrap(target, cbus.HandlerFunc(h.CreateClient)))
+	bus.Handle(&clientcmd.UpdateClientCommand{}, wrap(target, cbus.HandlerFunc(h.UpdateClient)))
+	bus.Handle(&clientcmd.DeleteClientCommand{}, wrap(target, cbus.HandlerFunc(h.DeleteClient)))
+}
+
+func RegisterWebhookCommands(bus *cbus.Bus, webhooks webhook.Repo, deliveries webhook.DeliveryRepo, wrap HandlerWrapper) {
+	h := &webhookcmd.Handler{
+		Webhooks:   webhooks,
+		Deliveries: deliveries,
+	}
+
+	target := WebhookAuditTarget(webhooks)
+
+	bus.Handle(&webhookcmd.CreateWebhookCommand{}, wrap(target, cbus.HandlerFunc(h.CreateWebhook)))
+	bus.Handle(&webhookcmd.UpdateWebhookCommand{}, wrap(target, cbus.HandlerFunc(h.UpdateWebhook)))
+	bus.Handle(&webhookcmd.DeleteWebhookCommand{}, wrap(target, cbus.HandlerFunc(h.DeleteWebhook)))
+	bus.Handle(&webhookcmd.RedeliverCommand{}, wrap(DeliveryAuditTarget(deliveries), cbus.HandlerFunc(h.Redeliver)))
+}
+
+func RegisterAPIKeyCommands(bus *cbus.Bus, keys apikey.Repo, wrap HandlerWrapper) {
+	h := &apikeycmd.Handler{
+		Keys: keys,
+	}
+
+	target := APIKeyAuditTarget(keys)
+
+	bus.Handle(&apikeycmd.IssueKeyCommand{}, wrap(target, cbus.HandlerFunc(h.IssueKey)))
+	bus.Handle(&apikeycmd.RevokeKeyCommand{}, wrap(target, cbus.HandlerFunc(h.RevokeKey)))
+}
+
+//Transactional returns a cbus.Handler that executes next inside of a single
+//transaction from transactor.
+//The transaction is rolled back if next returns an error.
+func Transactional(transactor data.Transactor, next cbus.Handler) cbus.Handler {
+	return cbus.HandlerFunc(func(ctx context.Context, cmd cbus.Command) (interface{}, error) {
+		var result interface{}
+
+		err := transactor.Transact(ctx, func(ctx context.Context) error {
+			var err error
+			result, err = next.Handle(ctx, cmd)
+			return err
+		})
+
+		return result, err
+	})
+}
+
+//UserAuditTarget returns the audit.Target for user commands that snapshots
+//Users from users.
+func UserAuditTarget(users user.QueryRepo) audit.Target {
+	return audit.Target{
+		Entity: user.EntityName,
+		Id: func(cmd cbus.Command, result interface{}) (data.Id, bool) {
+			switch cmd := cmd.(type) {
+			case *usercmd.UpdateUserCommand:
+				return cmd.Id, true
+			case *usercmd.DeleteUserCommand:
+				return cmd.Id, true
+			case *usercmd.AddUserClientCommand:
+				return cmd.UserId, true
+			case *usercmd.RemoveUserClientCommand:
+				return cmd.UserId, true
+			}
+			if u, ok := result.(*user.User); ok {
+				return u.Id, true
+			}
+			return data.EmptyId(), false
+		},
+		Snapshot: func(ctx context.Context, id data.Id) (interface{}, error) {
+			return users.Get(ctx, id)
+		},
 	}
+}
 
-	bus.Handle(&clientcmd.CreateClientCommand{}, cbus.HandlerFunc(h.CreateClient))
-	bus.Handle(&clientcmd.UpdateClientCommand{}, cbus.HandlerFunc(h.UpdateClient))
-	bus.Handle(&clientcmd.DeleteClientCommand{}, cbus.HandlerFunc(h.DeleteClient))
+//ClientAuditTarget returns the audit.Target for client commands that snapshots
+//Clients from clients.
+func ClientAuditTarget(clients client.QueryRepo) audit.Target {
+	return audit.Target{
+		Entity: client.EntityName,
+		Id: func(cmd cbus.Command, result interface{}) (data.Id, bool) {
+			switch cmd := cmd.(type) {
+			case *clientcmd.UpdateClientCommand:
+				return cmd.Id, true
+			case *clientcmd.DeleteClientCommand:
+				return cmd.Id, true
+			}
+			if c, ok := result.(*client.Client); ok {
+				return c.Id, true
+			}
+			return data.EmptyId(), false
+		},
+		Snapshot: func(ctx context.Context, id data.Id) (interface{}, error) {
+			return clients.Get(ctx, id)
+		},
+	}
+}
+
+//WebhookAuditTarget returns the audit.Target for webhook commands that snapshots
+//Webhooks from webhooks.
+//Webhook secrets are never part of a snapshot.
+func WebhookAuditTarget(webhooks webhook.QueryRepo) audit.Target {
+	return audit.Target{
+		Entity: webhook.EntityName,
+		Id: func(cmd cbus.Command, result interface{}) (data.Id, bool) {
+			switch cmd := cmd.(type) {
+			case *webhookcmd.UpdateWebhookCommand:
+				return cmd.Id, true
+			case *webhookcmd.DeleteWebhookCommand:
+				return cmd.Id, true
+			}
+			if w, ok := result.(*webhook.Webhook); ok {
+				return w.Id, true
+			}
+			return data.EmptyId(), false
+		},
+		Snapshot: func(ctx context.Context, id data.Id) (interface{}, error) {
+			return webhooks.Get(ctx, id)
+		},
+	}
+}
+
+//DeliveryAuditTarget returns the audit.Target for the redeliver command that
+//snapshots the new Delivery from deliveries.
+func DeliveryAuditTarget(deliveries webhook.DeliveryQueryRepo) audit.Target {
+	return audit.Target{
+		Entity: webhook.DeliveryEntityName,
+		Id: func(cmd cbus.Command, result interface{}) (data.Id, bool) {
+			if d, ok := result.(*webhook.Delivery); ok {
+				return d.Id, true
+			}
+			return data.EmptyId(), false
+		},
+		Snapshot: func(ctx context.Context, id data.Id) (interface{}, error) {
+			return deliveries.Get(ctx, id)
+		},
+	}
+}
+
+//APIKeyAuditTarget returns the audit.Target for api key commands that snapshots
+//Keys from keys.
+//Key hashes and tokens are never part of a snapshot.
+func APIKeyAuditTarget(keys apikey.QueryRepo) audit.Target {
+	return audit.Target{
+		Entity: apikey.EntityName,
+		Id: func(cmd cbus.Command, result interface{}) (data.Id, bool) {
+			if cmd, ok := cmd.(*apikeycmd.RevokeKeyCommand); ok {
+				return cmd.Id, true
+			}
+			if k, ok := result.(*apikey.Key); ok {
+				return k.Id, true
+			}
+			return data.EmptyId(), false
+		},
+		Snapshot: func(ctx context.Context, id data.Id) (interface{}, error) {
+			return keys.Get(ctx, id)
+		},
+	}
 }
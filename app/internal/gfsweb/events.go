@@ -0,0 +1,128 @@
+package gfsweb
+
+import (
+	"log"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event/eventstream"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/outbox"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/dto"
+)
+
+//CreateEventBus returns a new event.Bus with a webhook.Dispatcher subscribed
+//to all events that sends them to Webhooks as their dto.Event representation.
+//
+//Replace AppBuilder.EventBusFactory with a function that calls this one and
+//subscribes to the returned event.Bus to react to the application's events.
+func CreateEventBus(repos *Repos) *event.Bus {
+	bus := &event.Bus{}
+
+	bus.Subscribe(event.All, &webhook.Dispatcher{
+		Webhooks:   repos.Webhooks,
+		Deliveries: repos.WebhookDeliveries,
+		Data:       dto.Event,
+	})
+
+	return bus
+}
+
+//CreateRelay returns a new outbox.Relay that delivers the events in repos.Outbox
+//to events.
+//Errors while relaying are logged.
+func CreateRelay(repos *Repos, events event.Publisher) *outbox.Relay {
+	return &outbox.Relay{
+		Messages:  repos.Outbox,
+		Registry:  NewEventRegistry(),
+		Publisher: events,
+		OnError: func(err error) {
+			log.Printf("gfsweb: relaying events: %v", err)
+		},
+	}
+}
+
+//NewEventRegistry returns a new event.Registry with all of the application's
+//events registered.
+func NewEventRegistry() *event.Registry {
+	registry := &event.Registry{}
+
+	registry.Register(
+		user.UserCreated{},
+		user.UserUpdated{},
+		user.UserDeleted{},
+		user.UserClientsChanged{},
+		client.ClientCreated{},
+		client.ClientRenamed{},
+		client.ClientDeleted{},
+	)
+
+	return registry
+}
+
+//CreateWebhookWorker returns a new webhook.Worker that attempts the Deliveries
+//in repos.
+//Errors while working are logged.
+func CreateWebhookWorker(repos *Repos) *webhook.Worker {
+	return &webhook.Worker{
+		Webhooks:   repos.Webhooks,
+		Deliveries: repos.WebhookDeliveries,
+		OnError: func(err error) {
+			log.Printf("gfsweb: delivering webhooks: %v", err)
+		},
+	}
+}
+
+//CreateEventStream returns a new eventstream.Stream of the application's user
+//and client events described by DescribeEvent.
+func CreateEventStream() *eventstream.Stream {
+	return &eventstream.Stream{
+		Describe: DescribeEvent,
+	}
+}
+
+//DescribeEvent returns the eventstream.Notification for the user and client
+//events in e.
+//The Notifications of User events include the User's client ids so they can be
+//filtered by Client.
+func DescribeEvent(e event.Event) (eventstream.Notification, bool) {
+	switch e := e.(type) {
+	case user.UserCreated:
+		return userNotification(e.User.Id, e.User.ClientIds()), true
+	case user.UserUpdated:
+		return userNotification(e.User.Id, e.User.ClientIds()), true
+	case user.UserDeleted:
+		return userNotification(e.UserId, e.ClientIds), true
+	case user.UserClientsChanged:
+		//Clients the User was removed from are interested too.
+		clientIds := append(append([]data.Id{}, e.ClientIds...), e.Removed...)
+		return userNotification(e.UserId, clientIds), true
+
+	case client.ClientCreated:
+		return clientNotification(e.Client.Id), true
+	case client.ClientRenamed:
+		return clientNotification(e.ClientId), true
+	case client.ClientDeleted:
+		return clientNotification(e.ClientId), true
+	}
+
+	return eventstream.Notification{}, false
+}
+
+func userNotification(userId data.Id, clientIds []data.Id) eventstream.Notification {
+	return eventstream.Notification{
+		Entity:    user.EntityName,
+		EntityId:  userId,
+		ClientIds: clientIds,
+	}
+}
+
+func clientNotification(clientId data.Id) eventstream.Notification {
+	return eventstream.Notification{
+		Entity:    client.EntityName,
+		EntityId:  clientId,
+		ClientIds: []data.Id{clientId},
+	}
+}
@@ -1,14 +1,22 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/audit"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event/eventstream"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/dto"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/validate"
 	"github.com/gogolfing/cbus"
 	"github.com/gorilla/mux"
 )
@@ -27,11 +35,43 @@ type API struct {
 	//within the API.
 	Bus *cbus.Bus
 
+	//Transactor is used to execute the commands of a batch inside of a single
+	//transaction.
+	Transactor data.Transactor
+
 	//Users is a query repository used to retrieve Users.
 	Users user.QueryRepo
 
 	//Clients is a query repository used to retrieve Clients.
 	Clients client.QueryRepo
+
+	//Audit is a query repository used to retrieve audit Entries.
+	Audit audit.QueryRepo
+
+	//Changes is a query repository used to retrieve the change feed.
+	Changes change.QueryRepo
+
+	//Webhooks is a query repository used to retrieve Webhooks.
+	Webhooks webhook.QueryRepo
+
+	//WebhookDeliveries is a query repository used to retrieve webhook Deliveries.
+	WebhookDeliveries webhook.DeliveryQueryRepo
+
+	//APIKeys is a query repository used to retrieve and authenticate api Keys.
+	APIKeys apikey.QueryRepo
+
+	//Stream is used to stream change notifications to clients.
+	Stream *eventstream.Stream
+
+	//ExposeInternalErrors is whether or not the details of internal errors are
+	//sent in responses.
+	//It should only be true in development since details can include database
+	//messages.
+	ExposeInternalErrors bool
+
+	//AuthDisabled is whether or not requests are served without an api Key.
+	//It should only be true in development and tests.
+	AuthDisabled bool
 }
 
 //Handler returns an http.Handler that serves all requests for a.
@@ -45,12 +85,30 @@ func (a *API) Handler() http.Handler {
 	router.HandleFunc("/users", a.createUser).
 		Methods(http.MethodPost)
 
+	router.HandleFunc("/users.csv", a.exportUsers).
+		Methods(http.MethodGet)
+
+	router.HandleFunc("/users/import", a.importUsers).
+		Methods(http.MethodPost)
+
+	router.HandleFunc("/users:batch", a.batchUsers).
+		Methods(http.MethodPost)
+
 	router.HandleFunc("/users/{"+routeParamUserId+"}", a.getUser).
 		Methods(http.MethodGet)
 
 	router.HandleFunc("/users/{"+routeParamUserId+"}", a.updateUser).
 		Methods(http.MethodPatch)
 
+	router.HandleFunc("/users/{"+routeParamUserId+"}", a.deleteUser).
+		Methods(http.MethodDelete)
+
+	router.HandleFunc("/users/{"+routeParamUserId+"}/clients/{"+routeParamClientId+"}", a.addUserClient).
+		Methods(http.MethodPut)
+
+	router.HandleFunc("/users/{"+routeParamUserId+"}/clients/{"+routeParamClientId+"}", a.removeUserClient).
+		Methods(http.MethodDelete)
+
 	//Client routes.
 	router.HandleFunc("/clients", a.listClients).
 		Methods(http.MethodGet)
@@ -58,6 +116,12 @@ func (a *API) Handler() http.Handler {
 	router.HandleFunc("/clients", a.createClient).
 		Methods(http.MethodPost)
 
+	router.HandleFunc("/clients.csv", a.exportClients).
+		Methods(http.MethodGet)
+
+	router.HandleFunc("/clients:batch", a.batchClients).
+		Methods(http.MethodPost)
+
 	router.HandleFunc("/clients/{"+routeParamClientId+"}", a.getClient).
 		Methods(http.MethodGet)
 
@@ -67,7 +131,71 @@ func (a *API) Handler() http.Handler {
 	router.HandleFunc("/clients/{"+routeParamClientId+"}", a.deleteClient).
 		Methods(http.MethodDelete)
 
-	return router
+	router.HandleFunc("/clients/{"+routeParamClientId+"}/users", a.listClientUsers).
+		Methods(http.MethodGet)
+
+	//Webhook routes.
+	router.HandleFunc("/webhooks", a.listWebhooks).
+		Methods(http.MethodGet)
+
+	router.HandleFunc("/webhooks", a.createWebhook).
+		Methods(http.MethodPost)
+
+	router.HandleFunc("/webhooks/{"+routeParamWebhookId+"}", a.getWebhook).
+		Methods(http.MethodGet)
+
+	router.HandleFunc("/webhooks/{"+routeParamWebhookId+"}", a.updateWebhook).
+		Methods(http.MethodPatch)
+
+	router.HandleFunc("/webhooks/{"+routeParamWebhookId+"}", a.deleteWebhook).
+		Methods(http.MethodDelete)
+
+	router.HandleFunc("/webhooks/{"+routeParamWebhookId+"}/deliveries", a.listDeliveries).
+		Methods(http.MethodGet)
+
+	router.HandleFunc("/webhooks/{"+routeParamWebhookId+"}/deliveries/{"+routeParamDeliveryId+"}/redeliver", a.redeliver).
+		Methods(http.MethodPost)
+
+	//API key routes.
+	router.HandleFunc("/api-keys", a.createAPIKey).
+		Methods(http.MethodPost)
+
+	router.HandleFunc("/api-keys/{"+routeParamAPIKeyId+"}", a.getAPIKey).
+		Methods(http.MethodGet)
+
+	router.HandleFunc("/api-keys/{"+routeParamAPIKeyId+"}", a.revokeAPIKey).
+		Methods(http.MethodDelete)
+
+	//Event stream routes.
+	router.HandleFunc(pathEvents, a.streamEvents).
+		Methods(http.MethodGet)
+
+	//Change feed routes.
+	router.HandleFunc("/changes", a.listChanges).
+		Methods(http.MethodGet)
+
+	//Audit routes.
+	router.HandleFunc("/audit", a.listAudit).
+		Methods(http.MethodGet)
+
+	router.NotFoundHandler = http.HandlerFunc(a.notFound)
+
+	return withRequestId(a.withAuth(withActor(router)))
+}
+
+//withActor returns an http.Handler that records the request's apikey.Principal,
+//or its remote address if it is not authenticated, as the actor of any commands
+//executed while serving it.
+func withActor(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := r.RemoteAddr
+		if principal, ok := apikey.PrincipalFromContext(r.Context()); ok {
+			actor = principal.String()
+		}
+
+		ctx := audit.ContextWithActor(r.Context(), actor)
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
 }
 
 //idFrom is a helper method to retrieve data.Id(s) from route parameters.
@@ -91,16 +219,37 @@ func (a *API) idFrom(w http.ResponseWriter, r *http.Request, varName string) (da
 
 //parseForm is a helper method to parse form types that this package knows how
 //to use.
+//The rules declared in f's valid tags are enforced, and a 422 listing the invalid
+//fields is sent if any are not satisfied.
 func (a *API) parseForm(w http.ResponseWriter, r *http.Request, f interface{}) bool {
+	return a.decodeForm(w, r, f, false)
+}
+
+//parseOptionalForm is a helper method like parseForm, except that an empty
+//request body is allowed and leaves f unchanged.
+func (a *API) parseOptionalForm(w http.ResponseWriter, r *http.Request, f interface{}) bool {
+	return a.decodeForm(w, r, f, true)
+}
+
+//decodeForm is a helper method to decode and validate f for parseForm and
+//parseOptionalForm.
+func (a *API) decodeForm(w http.ResponseWriter, r *http.Request, f interface{}, optional bool) bool {
 	dec := json.NewDecoder(r.Body)
 
 	err := dec.Decode(f)
+	if optional && err == io.EOF {
+		err = nil
+	}
 	if err != nil {
 		a.sendError(w, err, http.StatusBadRequest)
 		return false
 	}
 
-	//Do some sort of validation here on f.
+	if err := validate.Struct(f); err != nil {
+		//Errors other than a *validate.Error are from the form's tags, not the request.
+		a.sendError(w, err, http.StatusInternalServerError)
+		return false
+	}
 
 	return true
 }
@@ -117,30 +266,33 @@ func (a *API) sendData(w http.ResponseWriter, data interface{}, status int) {
 	a.sendResponse(w, transformed, status)
 }
 
-//sendError is a helper method to send failure responses by marshaling err into
-//a simple value and sending status.
-func (a *API) sendError(w http.ResponseWriter, err error, status int) {
-	a.sendResponse(
-		w,
-		&apiError{
-			Error: err.Error(),
-		},
-		status,
-	)
-}
-
 //sendResponse is a helper method to send all responses by marshaling resp and
 //sending status.
 func (a *API) sendResponse(w http.ResponseWriter, resp interface{}, status int) {
-	w.Header().Add("Content-Type", "application/json")
-	w.WriteHeader(status)
+	body, err := encodeResponse(resp)
+	if err != nil {
+		//There is nothing else we can send if an error cannot be encoded.
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeResponse(w, body, status)
+}
 
-	enc := json.NewEncoder(w)
+//encodeResponse marshals resp into the body of a response.
+func encodeResponse(resp interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	enc := json.NewEncoder(buf)
 	enc.SetIndent("", "\t") //Do this for prettier output for our example application.
-	enc.Encode(resp)
+	err := enc.Encode(resp)
+
+	return buf.Bytes(), err
 }
 
-//apiError is a simple type that knows how to marshal an error.
-type apiError struct {
-	Error string `json:"error"`
+//writeResponse writes body as the JSON response with status.
+func writeResponse(w http.ResponseWriter, body []byte, status int) {
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
 }
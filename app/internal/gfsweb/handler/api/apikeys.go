@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey/apikeycmd"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/dto"
+)
+
+const (
+	routeParamAPIKeyId = "api_key_id"
+)
+
+//getAPIKey retrieves and sends a single api Key.
+func (a *API) getAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyId, ok := a.idFrom(w, r, routeParamAPIKeyId)
+	if !ok {
+		return
+	}
+
+	key, err := a.APIKeys.Get(r.Context(), keyId)
+	if err != nil {
+		//A not found error is sent as a 404 by sendError.
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, key)
+	a.sendData(w, key, http.StatusOK)
+}
+
+//createAPIKey attempts to issue a new api Key from a dto.CreateAPIKey form and
+//executing an apikeycmd.IssueKeyCommand.
+//
+//The Key's token is only ever sent in this response.
+func (a *API) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	f := &dto.CreateAPIKey{}
+	if ok := a.parseForm(w, r, f); !ok {
+		return
+	}
+
+	command := &apikeycmd.IssueKeyCommand{
+		Name: f.Name,
+	}
+
+	result, err := a.Bus.ExecuteContext(r.Context(), command)
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	key := result.(*apikey.Key)
+	output := dto.APIKey(key).(*dto.APIKeyOutput)
+	output.Token = key.Token
+
+	setETag(w, key)
+	a.sendResponse(w, output, http.StatusCreated)
+}
+
+//revokeAPIKey attempts to revoke an existing api Key from the route parameter
+//id by executing an apikeycmd.RevokeKeyCommand.
+//The revoked Key is sent in the response, and revoking it again is not an error.
+//
+//The If-Match header, if present, must match the Key's current ETag.
+func (a *API) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyId, ok := a.idFrom(w, r, routeParamAPIKeyId)
+	if !ok {
+		return
+	}
+
+	version, ok := a.ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	command := &apikeycmd.RevokeKeyCommand{
+		Id:      keyId,
+		Version: version,
+	}
+
+	key, err := a.Bus.ExecuteContext(r.Context(), command)
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, key)
+	a.sendData(w, key, http.StatusOK)
+}
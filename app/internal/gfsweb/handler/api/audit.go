@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/audit"
+)
+
+const (
+	queryParamEntityId = "entity_id"
+	queryParamSince    = "since"
+	queryParamUntil    = "until"
+)
+
+//listAudit retrieves and sends a single page of audit Entries that match the
+//criteria in the query parameters.
+func (a *API) listAudit(w http.ResponseWriter, r *http.Request) {
+	req, ok := a.pageRequestFrom(w, r)
+	if !ok {
+		return
+	}
+
+	criteria := audit.Criteria{}
+	if criteria.EntityId, ok = a.queryId(w, r, queryParamEntityId); !ok {
+		return
+	}
+	if criteria.Since, ok = a.queryTime(w, r, queryParamSince); !ok {
+		return
+	}
+	if criteria.Until, ok = a.queryTime(w, r, queryParamUntil); !ok {
+		return
+	}
+
+	entries, page, err := a.Audit.ListPage(r.Context(), criteria, req)
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	a.sendPage(w, r, entries, req, page)
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey"
+)
+
+const (
+	headerAuthorization   = "Authorization"
+	headerWWWAuthenticate = "WWW-Authenticate"
+)
+
+//authSchemeBearer is the Authorization scheme requests send api Key tokens with.
+const authSchemeBearer = "Bearer"
+
+//queryParamAccessToken is the query parameter GET /events requests may send an
+//api Key token in instead, since browser EventSource clients cannot set headers.
+const queryParamAccessToken = "access_token"
+
+var (
+	//ErrMissingAPIKey is a sentinel error indicating that a request does not
+	//have a Bearer token in its Authorization header.
+	ErrMissingAPIKey = errors.New("api: missing bearer api key")
+
+	//ErrInvalidAPIKey is a sentinel error indicating that a request's Bearer
+	//token is not the token of an api Key that has not been revoked.
+	ErrInvalidAPIKey = errors.New("api: invalid api key")
+)
+
+//withAuth returns an http.Handler that only serves requests to h that are
+//authenticated by the Bearer token of an api Key in a.APIKeys.
+//The Key's apikey.Principal is put into the context of authenticated requests.
+//
+//Requests to stream events may send the token in the access_token query
+//parameter instead.
+//All requests are served without authentication if a.AuthDisabled is true.
+func (a *API) withAuth(h http.Handler) http.Handler {
+	if a.AuthDisabled {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.authenticate(r)
+		if err != nil {
+			if err == ErrMissingAPIKey || err == ErrInvalidAPIKey {
+				w.Header().Set(headerWWWAuthenticate, authSchemeBearer)
+			}
+			a.sendError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		ctx := apikey.ContextWithPrincipal(r.Context(), principal)
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+//authenticate is a helper method to return the apikey.Principal of the api Key
+//whose token is in r's Authorization header.
+func (a *API) authenticate(r *http.Request) (apikey.Principal, error) {
+	token := bearerToken(r)
+	if token == "" && r.Method == http.MethodGet && r.URL.Path == pathEvents {
+		token = strings.TrimSpace(r.URL.Query().Get(queryParamAccessToken))
+	}
+	if token == "" {
+		return apikey.Principal{}, ErrMissingAPIKey
+	}
+
+	key, err := a.APIKeys.GetHash(r.Context(), apikey.Hash(token))
+	if data.IsNotFound(err) {
+		return apikey.Principal{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return apikey.Principal{}, err
+	}
+
+	if key.Revoked() {
+		return apikey.Principal{}, ErrInvalidAPIKey
+	}
+
+	return apikey.NewPrincipal(key), nil
+}
+
+//bearerToken returns the token in r's Authorization header, or the empty string
+//if it does not have a Bearer token.
+func bearerToken(r *http.Request) string {
+	parts := strings.SplitN(strings.TrimSpace(r.Header.Get(headerAuthorization)), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], authSchemeBearer) {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
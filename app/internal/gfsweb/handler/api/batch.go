@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/dto"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/validate"
+	"github.com/gogolfing/cbus"
+)
+
+//MaxBatchOperations is the maximum number of operations allowed in a single batch.
+const MaxBatchOperations = 100
+
+var (
+	//ErrUnknownBatchMode is a sentinel error indicating that a batch's mode is
+	//not one of the dto batch modes.
+	ErrUnknownBatchMode = errors.New("api: unknown batch mode")
+
+	//ErrUnknownBatchOp is a sentinel error indicating that a batch operation's op
+	//is not one of the dto batch operations.
+	ErrUnknownBatchOp = errors.New("api: unknown batch operation")
+
+	//ErrBatchTooLarge is a sentinel error indicating that a batch has more than
+	//MaxBatchOperations operations.
+	ErrBatchTooLarge = errors.New("api: too many batch operations")
+)
+
+//batchCommandFunc returns the command to execute for op, and the status to send
+//for op if the command succeeds.
+//The status returned with an error is the default status for that error.
+type batchCommandFunc func(op *dto.BatchOperation) (cbus.Command, int, error)
+
+//batchItem is a helper type to track a single operation of a batch.
+type batchItem struct {
+	command cbus.Command
+	status  int
+	result  interface{}
+	err     error
+}
+
+//batch attempts to execute the operations of a dto.Batch form with the commands
+//returned from command.
+//
+//All operations are executed through a.Bus inside of a single transaction from
+//a.Transactor, in order.
+//In dto.BatchModeAtomic, the first failed operation rolls back the entire batch
+//and its problem is sent with its index.
+//In dto.BatchModePartial, each operation that fails is rolled back on its own,
+//the rest are committed, and its problem is sent as its result.
+//
+//A dto.BatchOutput with a result for each operation is sent if the transaction
+//is committed.
+func (a *API) batch(w http.ResponseWriter, r *http.Request, command batchCommandFunc) {
+	f := &dto.Batch{}
+	if ok := a.parseForm(w, r, f); !ok {
+		return
+	}
+
+	atomic := true
+	switch f.Mode {
+	case "", dto.BatchModeAtomic:
+	case dto.BatchModePartial:
+		atomic = false
+	default:
+		a.sendError(w, ErrUnknownBatchMode, http.StatusBadRequest)
+		return
+	}
+
+	if len(f.Operations) > MaxBatchOperations {
+		a.sendError(w, ErrBatchTooLarge, http.StatusBadRequest)
+		return
+	}
+
+	items := make([]*batchItem, len(f.Operations))
+	for i := range f.Operations {
+		item := &batchItem{}
+		item.command, item.status, item.err = command(&f.Operations[i])
+
+		//There is no reason to start a transaction that is going to be rolled back.
+		if atomic && item.err != nil {
+			a.sendBatchError(w, i, item.err, item.status)
+			return
+		}
+
+		items[i] = item
+	}
+
+	failed := -1
+	err := a.Transactor.Transact(r.Context(), func(ctx context.Context) error {
+		for i, item := range items {
+			if item.err != nil {
+				continue
+			}
+
+			item.result, item.err = a.Bus.ExecuteContext(ctx, item.command)
+			if item.err != nil {
+				item.status = http.StatusInternalServerError
+				if atomic {
+					failed = i
+					return item.err
+				}
+			}
+		}
+		return nil
+	})
+	if failed >= 0 {
+		a.sendBatchError(w, failed, err, http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	a.sendBatchResults(w, items)
+}
+
+//sendBatchError is a helper method to send the problem for err as the failure of
+//the batch operation at index.
+func (a *API) sendBatchError(w http.ResponseWriter, index int, err error, status int) {
+	resp := a.newProblem(w, err, status)
+	resp.Index = &index
+
+	a.sendProblem(w, resp)
+}
+
+//sendBatchResults is a helper method to send a dto.BatchOutput of items.
+func (a *API) sendBatchResults(w http.ResponseWriter, items []*batchItem) {
+	results := make([]*dto.BatchResultOutput, len(items))
+
+	for i, item := range items {
+		result := &dto.BatchResultOutput{
+			Index: i,
+		}
+
+		if item.err != nil {
+			resp := a.newProblem(w, item.err, item.status)
+			result.Status, result.Error = resp.Status, resp
+		} else {
+			transformed, err := dto.Transform(item.result)
+			if err != nil {
+				a.sendError(w, err, http.StatusInternalServerError)
+				return
+			}
+			result.Status, result.Data = item.status, transformed
+			result.ETag, _ = entityETag(item.result)
+		}
+
+		results[i] = result
+	}
+
+	a.sendResponse(w, &dto.BatchOutput{Results: results}, http.StatusOK)
+}
+
+//batchOperationId returns the id of an update operation.
+//A *validate.Error is returned if op does not have one.
+func batchOperationId(op *dto.BatchOperation) (data.Id, error) {
+	if op.Id == nil {
+		return data.EmptyId(), &validate.Error{
+			Fields: []validate.FieldError{{
+				Field:   "id",
+				Rule:    validate.RuleRequired,
+				Message: "is required",
+			}},
+		}
+	}
+	return *op.Id, nil
+}
+
+//parseBatchData parses op's Data into the form f and evaluates f's rules like
+//parseForm.
+func parseBatchData(op *dto.BatchOperation, f interface{}) error {
+	if err := json.Unmarshal(op.Data, f); err != nil {
+		return err
+	}
+	return validate.Struct(f)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/dto"
+)
+
+//listChanges retrieves and sends the Changes after the since query parameter in
+//a dto.ChangeFeedOutput envelope.
+//The Data of each Change is sent in the same form as the entity is elsewhere in
+//the API.
+//
+//Unlike other lists, the feed is read by sequence number instead of a cursor so
+//that consumers can store the last sequence number they processed and resume
+//exactly after it.
+func (a *API) listChanges(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	since := int64(0)
+	if value := query.Get(queryParamSince); value != "" {
+		var err error
+		since, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || since < 0 {
+			a.sendError(w, ErrBadRequestQueryParameter, http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit := data.DefaultPageLimit
+	if value := query.Get(queryParamLimit); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > data.MaxPageLimit {
+			a.sendError(w, data.ErrInvalidPageLimit, http.StatusBadRequest)
+			return
+		}
+	}
+
+	changes, err := a.Changes.ListSince(r.Context(), since, limit)
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	for _, c := range changes {
+		if c.Data, err = dto.ChangeData(c.Entity, c.Data); err != nil {
+			a.sendError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	transformed, err := dto.Transform(changes)
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	nextSince := since
+	if len(changes) > 0 {
+		nextSince = changes[len(changes)-1].Seq
+	}
+
+	next := *r.URL
+	nextQuery := next.Query()
+	nextQuery.Set(queryParamSince, strconv.FormatInt(nextSince, 10))
+	nextQuery.Set(queryParamLimit, strconv.Itoa(limit))
+	next.RawQuery = nextQuery.Encode()
+
+	a.sendResponse(w, &dto.ChangeFeedOutput{
+		Data:      transformed,
+		NextSince: nextSince,
+		Next:      next.RequestURI(),
+	}, http.StatusOK)
+}
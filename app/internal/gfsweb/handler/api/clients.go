@@ -3,34 +3,89 @@ package api
 import (
 	"net/http"
 
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client/clientcmd"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/dto"
+	"github.com/gogolfing/cbus"
 )
 
 const (
 	routeParamClientId = "client_id"
+
+	queryParamNamePrefix = "name_prefix"
+	queryParamHasUsers   = "has_users"
 )
 
 //getClient retrieves and sends a single Client.
+//A 304 is sent instead if the request's conditional headers match the Client.
 func (a *API) getClient(w http.ResponseWriter, r *http.Request) {
 	client, ok := a.getClientEntity(w, r)
 	if !ok {
 		return
 	}
 
+	setETag(w, client)
+	setLastModified(w, client)
+	if a.sendNotModified(w, r) {
+		return
+	}
+
 	a.sendData(w, client, http.StatusOK)
 }
 
-//listClients retrieves and sends all Clients.
+//listClients retrieves and sends a single page of Clients that match the
+//criteria in the query parameters.
 func (a *API) listClients(w http.ResponseWriter, r *http.Request) {
-	clients, err := a.Clients.List(r.Context())
+	req, ok := a.pageRequestFrom(w, r)
+	if !ok {
+		return
+	}
+
+	criteria := client.Criteria{
+		NamePrefix: r.URL.Query().Get(queryParamNamePrefix),
+	}
+	if criteria.HasUsers, ok = a.queryBool(w, r, queryParamHasUsers); !ok {
+		return
+	}
+
+	clients, page, err := a.Clients.ListPage(r.Context(), criteria, req)
 	if err != nil {
 		a.sendError(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	a.sendData(w, clients, http.StatusOK)
+	a.sendPage(w, r, clients, req, page)
+}
+
+//listClientUsers attempts to retrieve and send a single page of the Users that
+//belong to the Client from the route parameter id.
+//The response is the same as listUsers', and the Users' Clients are embedded if
+//the include query parameter has clients.
+func (a *API) listClientUsers(w http.ResponseWriter, r *http.Request) {
+	req, ok := a.pageRequestFrom(w, r)
+	if !ok {
+		return
+	}
+
+	withClients, ok := a.includesClients(w, r)
+	if !ok {
+		return
+	}
+
+	//Retrieve the Client so that a missing Client is not an empty page.
+	client, ok := a.getClientEntity(w, r)
+	if !ok {
+		return
+	}
+
+	users, page, err := a.Users.ListByClient(r.Context(), client.Id, req)
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	a.sendUserPage(w, r, users, withClients, req, page)
 }
 
 //createClient attempts to create a new Client from a dto.CreateClient form
@@ -41,52 +96,105 @@ func (a *API) createClient(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	command := &clientcmd.CreateClientCommand{
-		Name: f.Name,
-	}
-
-	client, err := a.Bus.ExecuteContext(r.Context(), command)
+	client, err := a.Bus.ExecuteContext(r.Context(), createClientCommand(f))
 	a.clientCommandResponse(w, client, err, http.StatusCreated)
 }
 
 //updateClient attempts to update an existing client from a dto.UpdateClient form
 //and executing a clientcmd.UpdateClientCommand.
+//
+//The If-Match header, if present, must match the Client's current ETag.
 func (a *API) updateClient(w http.ResponseWriter, r *http.Request) {
 	clientId, ok := a.idFrom(w, r, routeParamClientId)
 	if !ok {
 		return
 	}
 
+	version, ok := a.ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
 	f := &dto.UpdateClient{}
 	if ok := a.parseForm(w, r, f); !ok {
 		return
 	}
 
-	command := &clientcmd.UpdateClientCommand{
-		Id:   clientId,
+	client, err := a.Bus.ExecuteContext(r.Context(), updateClientCommand(clientId, version, f))
+	a.clientCommandResponse(w, client, err, http.StatusOK)
+}
+
+//createClientCommand returns the clientcmd.CreateClientCommand for f.
+func createClientCommand(f *dto.CreateClient) *clientcmd.CreateClientCommand {
+	return &clientcmd.CreateClientCommand{
 		Name: f.Name,
 	}
+}
 
-	client, err := a.Bus.ExecuteContext(r.Context(), command)
-	a.clientCommandResponse(w, client, err, http.StatusOK)
+//updateClientCommand returns the clientcmd.UpdateClientCommand for f that
+//updates the Client with id if it is at version.
+func updateClientCommand(id data.Id, version *int, f *dto.UpdateClient) *clientcmd.UpdateClientCommand {
+	return &clientcmd.UpdateClientCommand{
+		Id:      id,
+		Name:    f.Name,
+		Version: version,
+	}
+}
+
+//batchClients attempts to execute the create and update Client operations of a
+//dto.Batch form.
+//See batch.
+func (a *API) batchClients(w http.ResponseWriter, r *http.Request) {
+	a.batch(w, r, clientBatchCommand)
+}
+
+//clientBatchCommand returns the client command for op, which is either a
+//dto.CreateClient or a dto.UpdateClient form depending on op's Op.
+func clientBatchCommand(op *dto.BatchOperation) (cbus.Command, int, error) {
+	switch op.Op {
+	case dto.BatchOpCreate:
+		f := &dto.CreateClient{}
+		if err := parseBatchData(op, f); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		return createClientCommand(f), http.StatusCreated, nil
+
+	case dto.BatchOpUpdate:
+		id, err := batchOperationId(op)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		f := &dto.UpdateClient{}
+		if err := parseBatchData(op, f); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		return updateClientCommand(id, op.Version, f), http.StatusOK, nil
+	}
+
+	return nil, http.StatusBadRequest, ErrUnknownBatchOp
 }
 
 //deleteClient attempts to delete an existing Client the route parameter id
 //and executing a clientcmd.DeleteClientCommand.
+//
+//The If-Match header, if present, must match the Client's current ETag.
 func (a *API) deleteClient(w http.ResponseWriter, r *http.Request) {
-	//We still want to retrive the client in order to make sure it exists.
-	//If this operation fails, it would ideally result in a 404 or special entity
-	//not found error messaging.
-	//
-	//The delete command may not necessarily be able to indicate there was no
-	//entity to delete.
+	version, ok := a.ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	//We still want to retrive the client in order to make sure it exists and
+	//to send it in the response.
+	//If this operation fails because the Client does not exist, a 404 is sent.
 	client, ok := a.getClientEntity(w, r)
 	if !ok {
 		return
 	}
 
 	command := &clientcmd.DeleteClientCommand{
-		Id: client.Id,
+		Id:      client.Id,
+		Version: version,
 	}
 
 	_, err := a.Bus.ExecuteContext(r.Context(), command)
@@ -105,7 +213,7 @@ func (a *API) getClientEntity(w http.ResponseWriter, r *http.Request) (*client.C
 
 	client, err := a.Clients.Get(r.Context(), clientId)
 	if err != nil {
-		//Again, we can examine the error the see what type of status to send.
+		//A not found error is sent as a 404 by sendError.
 		a.sendError(w, err, http.StatusInternalServerError)
 		return nil, false
 	}
@@ -121,5 +229,6 @@ func (a *API) clientCommandResponse(w http.ResponseWriter, result interface{}, e
 		return
 	}
 
+	setETag(w, result)
 	a.sendData(w, result, okStatus)
 }
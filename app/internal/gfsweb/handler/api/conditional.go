@@ -0,0 +1,119 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+)
+
+const (
+	headerLastModified    = "Last-Modified"
+	headerIfNoneMatch     = "If-None-Match"
+	headerIfModifiedSince = "If-Modified-Since"
+)
+
+//setLastModified is a helper function to set the Last-Modified header from
+//entity's UpdatedAt field if entity is a known domain type.
+func setLastModified(w http.ResponseWriter, entity interface{}) {
+	var updatedAt time.Time
+
+	switch entity := entity.(type) {
+	case *user.User:
+		updatedAt = entity.UpdatedAt
+	case *client.Client:
+		updatedAt = entity.UpdatedAt
+	default:
+		return
+	}
+
+	w.Header().Set(headerLastModified, updatedAt.UTC().Format(http.TimeFormat))
+}
+
+//hashETag returns an entity tag that represents the content of body.
+func hashETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+//sendHashedResponse is a helper method to send resp with an ETag of its
+//marshaled content, or a 304 Not Modified response if r already has it.
+func (a *API) sendHashedResponse(w http.ResponseWriter, r *http.Request, resp interface{}) {
+	body, err := encodeResponse(resp)
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(headerETag, hashETag(body))
+	if a.sendNotModified(w, r) {
+		return
+	}
+
+	writeResponse(w, body, http.StatusOK)
+}
+
+//sendNotModified is a helper method that sends a 304 Not Modified response if
+//r's conditional headers indicate the client already has the representation
+//described by the ETag and Last-Modified headers already set on w.
+//It returns whether or not a response was sent.
+//
+//If-None-Match takes precedence over If-Modified-Since as described in RFC 7232.
+func (a *API) sendNotModified(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if ifNoneMatch := r.Header.Get(headerIfNoneMatch); ifNoneMatch != "" {
+		if !etagsMatch(ifNoneMatch, w.Header().Get(headerETag)) {
+			return false
+		}
+	} else if !notModifiedSince(r.Header.Get(headerIfModifiedSince), w.Header().Get(headerLastModified)) {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+//etagsMatch returns whether or not any of the comma separated entity tags in
+//list weakly match current.
+func etagsMatch(list, current string) bool {
+	if current == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(current, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+//notModifiedSince returns whether or not the lastModified header value is not
+//after the ifModifiedSince header value.
+//False is returned if either value is missing or invalid.
+func notModifiedSince(ifModifiedSince, lastModified string) bool {
+	if ifModifiedSince == "" || lastModified == "" {
+		return false
+	}
+
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+
+	return !modified.After(since)
+}
@@ -0,0 +1,250 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+)
+
+//ContentTypeCSV is the media type of CSV exports.
+const ContentTypeCSV = "text/csv; charset=utf-8"
+
+//CSV column names used by exports and imports.
+const (
+	csvColumnId          = "id"
+	csvColumnEmail       = "email"
+	csvColumnEnabled     = "enabled"
+	csvColumnClientNames = "client_names"
+	csvColumnName        = "name"
+	csvColumnCreatedAt   = "created_at"
+	csvColumnUpdatedAt   = "updated_at"
+)
+
+//csvListSeparator separates the values of a list in a single CSV cell, such as
+//the client names of a User.
+//Separators and csvListEscape inside of values are preceded by csvListEscape.
+const csvListSeparator = ';'
+
+//csvListEscape precedes a character in a CSV list that is part of a value.
+const csvListEscape = '\\'
+
+//csvFormulaPrefixes are the characters that make spreadsheet applications
+//evaluate a cell as a formula when it starts with one of them.
+const csvFormulaPrefixes = "=+-@\t\r"
+
+//csvFormulaEscape is prepended to exported cells that would otherwise be
+//evaluated as formulas, and is removed from them when they are imported.
+const csvFormulaEscape = "'"
+
+//exportUsers sends all Users that match the criteria in the query parameters
+//as a CSV file with the names of the Clients they belong to.
+func (a *API) exportUsers(w http.ResponseWriter, r *http.Request) {
+	criteria, ok := a.userCriteriaFrom(w, r)
+	if !ok {
+		return
+	}
+
+	users, err := a.allUsers(r.Context(), criteria)
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	names, err := a.clientNames(r.Context())
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	records := [][]string{{
+		csvColumnId,
+		csvColumnEmail,
+		csvColumnEnabled,
+		csvColumnClientNames,
+		csvColumnCreatedAt,
+		csvColumnUpdatedAt,
+	}}
+
+	for _, u := range users {
+		clientNames := make([]string, len(u.Memberships))
+		for i, m := range u.Memberships {
+			clientNames[i] = names[m.ClientId]
+		}
+
+		records = append(records, []string{
+			u.Id.String(),
+			u.Email,
+			strconv.FormatBool(u.Enabled),
+			joinCSVList(clientNames),
+			csvTime(u.CreatedAt),
+			csvTime(u.UpdatedAt),
+		})
+	}
+
+	a.sendCSV(w, "users.csv", records)
+}
+
+//exportClients sends all Clients as a CSV file.
+func (a *API) exportClients(w http.ResponseWriter, r *http.Request) {
+	clients, err := a.Clients.List(r.Context())
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	records := [][]string{{
+		csvColumnId,
+		csvColumnName,
+		csvColumnCreatedAt,
+		csvColumnUpdatedAt,
+	}}
+
+	for _, c := range clients {
+		records = append(records, []string{
+			c.Id.String(),
+			c.Name,
+			csvTime(c.CreatedAt),
+			csvTime(c.UpdatedAt),
+		})
+	}
+
+	a.sendCSV(w, "clients.csv", records)
+}
+
+//allUsers is a helper method to retrieve all Users that match criteria, one
+//page at a time.
+func (a *API) allUsers(ctx context.Context, criteria user.Criteria) ([]*user.User, error) {
+	result := []*user.User{}
+
+	page := data.PageRequest{Limit: data.MaxPageLimit}
+	for {
+		users, p, err := a.Users.ListPage(ctx, criteria, page)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, users...)
+
+		if p.Next == "" {
+			return result, nil
+		}
+		page.Cursor = p.Next
+	}
+}
+
+//clientNames is a helper method to retrieve the names of all Clients keyed by
+//their Ids.
+func (a *API) clientNames(ctx context.Context) (map[data.Id]string, error) {
+	clients, err := a.Clients.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[data.Id]string, len(clients))
+	for _, c := range clients {
+		result[c.Id] = c.Name
+	}
+
+	return result, nil
+}
+
+//sendCSV is a helper method to send records as a CSV file attachment named filename.
+//Cells are escaped with csvCell.
+func (a *API) sendCSV(w http.ResponseWriter, filename string, records [][]string) {
+	buf := &bytes.Buffer{}
+
+	for _, record := range records {
+		for i, value := range record {
+			record[i] = csvCell(value)
+		}
+	}
+
+	//Writing everything before sending lets errors still be sent as problems.
+	if err := csv.NewWriter(buf).WriteAll(records); err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentTypeCSV)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+//csvTime formats t for a CSV cell.
+func csvTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+//csvCell returns value escaped with csvFormulaEscape if it starts with one of
+//csvFormulaPrefixes so that spreadsheet applications do not evaluate it.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return csvFormulaEscape + value
+	}
+	return value
+}
+
+//csvUncell returns the value of an imported cell, which is cell without the
+//csvFormulaEscape that csvCell adds.
+func csvUncell(cell string) string {
+	if escaped := strings.TrimPrefix(cell, csvFormulaEscape); escaped != cell && csvCell(escaped) == cell {
+		return escaped
+	}
+	return cell
+}
+
+//joinCSVList returns values as a single CSV cell separated by csvListSeparator.
+func joinCSVList(values []string) string {
+	buf := &bytes.Buffer{}
+	for i, value := range values {
+		if i > 0 {
+			buf.WriteRune(csvListSeparator)
+		}
+		for _, r := range value {
+			if r == csvListSeparator || r == csvListEscape {
+				buf.WriteRune(csvListEscape)
+			}
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+//splitCSVList returns the values in cell, the result of joinCSVList.
+//Values are trimmed of surrounding spaces, and empty values are omitted.
+func splitCSVList(cell string) []string {
+	result := []string{}
+
+	value := &bytes.Buffer{}
+	add := func() {
+		if v := strings.TrimSpace(value.String()); v != "" {
+			result = append(result, v)
+		}
+		value.Reset()
+	}
+
+	escaped := false
+	for _, r := range cell {
+		switch {
+		case escaped:
+			value.WriteRune(r)
+			escaped = false
+		case r == csvListEscape:
+			escaped = true
+		case r == csvListSeparator:
+			add()
+		default:
+			value.WriteRune(r)
+		}
+	}
+	add()
+
+	return result
+}
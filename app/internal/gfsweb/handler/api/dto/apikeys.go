@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey"
+)
+
+//APIKey transforms a single *apikey.Key to an *APIKeyOutput.
+//The Key's Token is not included.
+func APIKey(v interface{}) interface{} {
+	k := v.(*apikey.Key)
+
+	return &APIKeyOutput{
+		Id:        k.Id,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		CreatedAt: k.CreatedAt,
+		RevokedAt: k.RevokedAt,
+	}
+}
+
+//APIKeyOutput is a marshalable type that should be used for external
+//representations of apikey.Key(s) outside the API.
+//Token is only set in the response to issuing a Key.
+type APIKeyOutput struct {
+	Id        data.Id    `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Token     string     `json:"token,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+//CreateAPIKey is a form type that should be used for incoming create api key
+//requests to the API.
+type CreateAPIKey struct {
+	Name string `json:"name" valid:"required"`
+}
@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/audit"
+)
+
+//AuditEntries transforms a []*audit.Entry to a []*AuditEntryOutput.
+//It delegates to AuditEntry.
+func AuditEntries(v interface{}) interface{} {
+	entries := v.([]*audit.Entry)
+
+	result := make([]interface{}, len(entries))
+	for i, e := range entries {
+		result[i] = AuditEntry(e)
+	}
+
+	return result
+}
+
+//AuditEntry transforms a single *audit.Entry to an *AuditEntryOutput.
+func AuditEntry(v interface{}) interface{} {
+	entry := v.(*audit.Entry)
+
+	return &AuditEntryOutput{
+		Id:        entry.Id,
+		Actor:     entry.Actor,
+		Command:   entry.Command,
+		Payload:   entry.Payload,
+		Entity:    entry.Entity,
+		EntityId:  entry.EntityId,
+		Before:    entry.Before,
+		After:     entry.After,
+		CreatedAt: entry.CreatedAt,
+	}
+}
+
+//AuditEntryOutput is a marshalable type that should be used for external
+//representations of audit.Entry(s) outside the API.
+//Before and After are null if the entity did not exist at that time.
+type AuditEntryOutput struct {
+	Id        data.Id         `json:"id"`
+	Actor     string          `json:"actor"`
+	Command   string          `json:"command"`
+	Payload   json.RawMessage `json:"payload"`
+	Entity    string          `json:"entity"`
+	EntityId  data.Id         `json:"entity_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	CreatedAt time.Time       `json:"created_at"`
+}
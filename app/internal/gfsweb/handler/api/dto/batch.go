@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"encoding/json"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//Batch modes.
+const (
+	//BatchModeAtomic applies every operation of a Batch or none of them.
+	BatchModeAtomic = "atomic"
+
+	//BatchModePartial applies every operation of a Batch that succeeds.
+	BatchModePartial = "partial"
+)
+
+//Batch operations.
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+)
+
+//Batch is a form type that should be used for incoming batch requests to the API.
+//Mode defaults to BatchModeAtomic.
+type Batch struct {
+	Mode       string           `json:"mode"`
+	Operations []BatchOperation `json:"operations" valid:"required"`
+}
+
+//BatchOperation is a single operation of a Batch.
+//
+//Data is the operation's create or update form, which is parsed once Op is known.
+//Id and Version are only used by update operations, with Version serving the
+//purpose of the If-Match header.
+type BatchOperation struct {
+	Op      string          `json:"op" valid:"required"`
+	Id      *data.Id        `json:"id"`
+	Version *int            `json:"version"`
+	Data    json.RawMessage `json:"data" valid:"required"`
+}
+
+//BatchOutput is a marshalable type that should be used to represent the results
+//of a Batch outside of the API.
+type BatchOutput struct {
+	Results []*BatchResultOutput `json:"results"`
+}
+
+//BatchResultOutput is a marshalable type that should be used to represent the
+//result of a single BatchOperation outside of the API.
+//
+//Status is the http status the operation would have had as its own request.
+//Data and ETag are set if it succeeded, and Error is set if it failed.
+type BatchResultOutput struct {
+	Index  int         `json:"index"`
+	Status int         `json:"status"`
+	ETag   string      `json:"etag,omitempty"`
+	Data   interface{} `json:"data,omitempty"`
+	Error  interface{} `json:"error,omitempty"`
+}
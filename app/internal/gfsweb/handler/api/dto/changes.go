@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+)
+
+//Changes transforms a []*change.Change to a []*ChangeOutput.
+//It delegates to Change.
+func Changes(v interface{}) interface{} {
+	changes := v.([]*change.Change)
+
+	result := make([]interface{}, len(changes))
+	for i, c := range changes {
+		result[i] = Change(c)
+	}
+
+	return result
+}
+
+//Change transforms a single *change.Change to a *ChangeOutput.
+func Change(v interface{}) interface{} {
+	c := v.(*change.Change)
+
+	return &ChangeOutput{
+		Seq:       c.Seq,
+		Entity:    c.Entity,
+		EntityId:  c.EntityId,
+		Op:        c.Op,
+		Data:      c.Data,
+		CreatedAt: c.CreatedAt,
+	}
+}
+
+//ChangeData returns the Data of a Change to the entity named entity as the
+//entity's output type, e.g. a *UserOutput for a user, instead of the domain type
+//it is recorded as.
+//Nil Data and the Data of unknown entities are returned unchanged.
+func ChangeData(entity string, raw json.RawMessage) (json.RawMessage, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var v interface{}
+	switch entity {
+	case user.EntityName:
+		u := &user.User{}
+		if err := json.Unmarshal(raw, u); err != nil {
+			return nil, err
+		}
+		v = User(u)
+	case client.EntityName:
+		c := &client.Client{}
+		if err := json.Unmarshal(raw, c); err != nil {
+			return nil, err
+		}
+		v = Client(c)
+	default:
+		return raw, nil
+	}
+
+	return json.Marshal(v)
+}
+
+//ChangeOutput is a marshalable type that should be used for external
+//representations of change.Change(s) outside the API.
+//Data is null for deletes, and should have been passed through ChangeData.
+type ChangeOutput struct {
+	Seq       int64           `json:"seq"`
+	Entity    string          `json:"entity"`
+	EntityId  data.Id         `json:"entity_id"`
+	Op        string          `json:"op"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+//ChangeFeedOutput is a marshalable envelope that should be used to represent a
+//single batch of the change feed outside of the API.
+type ChangeFeedOutput struct {
+	//Data is the transformed Changes in the batch.
+	Data interface{} `json:"data"`
+
+	//NextSince is the since value that continues the feed after this batch.
+	NextSince int64 `json:"next_since"`
+
+	//Next is the link that continues the feed after this batch.
+	Next string `json:"next"`
+}
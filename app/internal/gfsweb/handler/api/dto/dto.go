@@ -4,8 +4,13 @@ import (
 	"errors"
 	"reflect"
 
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/audit"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event/eventstream"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
 )
 
 //ErrUnknownTypeToTransform is a sentinel error indicating that a type requested
@@ -25,8 +30,27 @@ func init() {
 		reflect.TypeOf([]*user.User{}): Users,
 		reflect.TypeOf(&user.User{}):   User,
 
+		reflect.TypeOf([]*UserWithClientsView{}): UsersWithClients,
+		reflect.TypeOf(&UserWithClientsView{}):   UserWithClients,
+
 		reflect.TypeOf([]*client.Client{}): Clients,
 		reflect.TypeOf(&client.Client{}):   Client,
+
+		reflect.TypeOf([]*audit.Entry{}): AuditEntries,
+		reflect.TypeOf(&audit.Entry{}):   AuditEntry,
+
+		reflect.TypeOf([]*change.Change{}): Changes,
+		reflect.TypeOf(&change.Change{}):   Change,
+
+		reflect.TypeOf([]*webhook.Webhook{}): Webhooks,
+		reflect.TypeOf(&webhook.Webhook{}):   Webhook,
+
+		reflect.TypeOf([]*webhook.Delivery{}): Deliveries,
+		reflect.TypeOf(&webhook.Delivery{}):   Delivery,
+
+		reflect.TypeOf(&apikey.Key{}): APIKey,
+
+		reflect.TypeOf(&eventstream.Notification{}): Notification,
 	}
 }
 
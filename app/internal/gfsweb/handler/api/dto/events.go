@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+)
+
+//Event transforms an event.Event to a marshalable type that should be used for
+//external representations of events outside the API, such as the Data of
+//webhook Envelopes.
+//Created and updated events are represented by the entity after the change, and
+//other events by the Id of their entity and their details.
+//
+//ErrUnknownTypeToTransform is returned if e is an unknown event.
+func Event(e event.Event) (interface{}, error) {
+	switch e := e.(type) {
+	case user.UserCreated:
+		return User(&e.User), nil
+	case user.UserUpdated:
+		return User(&e.User), nil
+	case user.UserDeleted:
+		return &UserDeletedOutput{
+			UserId:    e.UserId,
+			ClientIds: nonNilIds(e.ClientIds),
+		}, nil
+	case user.UserClientsChanged:
+		return &UserClientsChangedEventOutput{
+			UserId: e.UserId,
+			UserClientsChangedOutput: UserClientsChangedOutput{
+				ClientIds: nonNilIds(e.ClientIds),
+				Added:     nonNilIds(e.Added),
+				Removed:   nonNilIds(e.Removed),
+			},
+		}, nil
+	case client.ClientCreated:
+		return Client(&e.Client), nil
+	case client.ClientRenamed:
+		return &ClientRenamedEventOutput{
+			ClientId: e.ClientId,
+			ClientRenamedOutput: ClientRenamedOutput{
+				OldName: e.OldName,
+				NewName: e.NewName,
+			},
+		}, nil
+	case client.ClientDeleted:
+		return &ClientDeletedOutput{
+			ClientId: e.ClientId,
+		}, nil
+	}
+	return nil, ErrUnknownTypeToTransform
+}
+
+//UserDeletedOutput is the representation of a user.UserDeleted event.
+type UserDeletedOutput struct {
+	UserId    data.Id   `json:"user_id"`
+	ClientIds []data.Id `json:"client_ids"`
+}
+
+//UserClientsChangedEventOutput is the representation of a user.UserClientsChanged
+//event.
+type UserClientsChangedEventOutput struct {
+	UserId data.Id `json:"user_id"`
+	UserClientsChangedOutput
+}
+
+//ClientRenamedEventOutput is the representation of a client.ClientRenamed event.
+type ClientRenamedEventOutput struct {
+	ClientId data.Id `json:"client_id"`
+	ClientRenamedOutput
+}
+
+//ClientDeletedOutput is the representation of a client.ClientDeleted event.
+type ClientDeletedOutput struct {
+	ClientId data.Id `json:"client_id"`
+}
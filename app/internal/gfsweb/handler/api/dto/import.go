@@ -0,0 +1,29 @@
+package dto
+
+import "github.com/AgencyPMG/go-from-scratch/app/internal/data"
+
+//ImportOutput is a marshalable type that should be used to report the result
+//of an import outside of the API.
+//
+//If DryRun is true, nothing was actually imported and Rows report what would
+//have happened.
+type ImportOutput struct {
+	DryRun  bool               `json:"dry_run"`
+	Created int                `json:"created"`
+	Updated int                `json:"updated"`
+	Failed  int                `json:"failed"`
+	Rows    []*ImportRowOutput `json:"rows"`
+}
+
+//ImportRowOutput is a marshalable type that should be used to report the result
+//of a single imported row outside of the API.
+//
+//Row is the row's number, where the header is row 1.
+//Id is the imported entity's id, and Error is set if the row failed.
+type ImportRowOutput struct {
+	Row    int         `json:"row"`
+	Email  string      `json:"email"`
+	Status string      `json:"status"`
+	Id     *data.Id    `json:"id,omitempty"`
+	Error  interface{} `json:"error,omitempty"`
+}
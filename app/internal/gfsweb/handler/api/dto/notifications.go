@@ -0,0 +1,91 @@
+package dto
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event/eventstream"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+)
+
+//Notification transforms a single *eventstream.Notification to a
+//*NotificationOutput.
+func Notification(v interface{}) interface{} {
+	n := v.(*eventstream.Notification)
+
+	clientIds := n.ClientIds
+	if clientIds == nil {
+		clientIds = []data.Id{}
+	}
+
+	return &NotificationOutput{
+		Id:        strconv.FormatUint(n.Id, 10),
+		Event:     n.Event.EventName(),
+		Entity:    n.Entity,
+		EntityId:  n.EntityId,
+		ClientIds: clientIds,
+		CreatedAt: n.CreatedAt,
+		Data:      eventData(n.Event),
+	}
+}
+
+//eventData returns the marshalable details of e, or nil if there are none
+//beyond the entity's Id.
+func eventData(e event.Event) interface{} {
+	switch e := e.(type) {
+	case user.UserCreated:
+		return User(&e.User)
+	case user.UserUpdated:
+		return User(&e.User)
+	case user.UserClientsChanged:
+		return &UserClientsChangedOutput{
+			ClientIds: nonNilIds(e.ClientIds),
+			Added:     nonNilIds(e.Added),
+			Removed:   nonNilIds(e.Removed),
+		}
+	case client.ClientCreated:
+		return Client(&e.Client)
+	case client.ClientRenamed:
+		return &ClientRenamedOutput{
+			OldName: e.OldName,
+			NewName: e.NewName,
+		}
+	}
+	return nil
+}
+
+func nonNilIds(ids []data.Id) []data.Id {
+	if ids == nil {
+		return []data.Id{}
+	}
+	return ids
+}
+
+//NotificationOutput is a marshalable type that should be used for external
+//representations of eventstream.Notification(s) outside the API.
+//Data is the entity after the change for created and updated events.
+type NotificationOutput struct {
+	Id        string      `json:"id"`
+	Event     string      `json:"event"`
+	Entity    string      `json:"entity"`
+	EntityId  data.Id     `json:"entity_id"`
+	ClientIds []data.Id   `json:"client_ids"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+//UserClientsChangedOutput is the Data of a user.UserClientsChanged Notification.
+type UserClientsChangedOutput struct {
+	ClientIds []data.Id `json:"client_ids"`
+	Added     []data.Id `json:"added"`
+	Removed   []data.Id `json:"removed"`
+}
+
+//ClientRenamedOutput is the Data of a client.ClientRenamed Notification.
+type ClientRenamedOutput struct {
+	OldName string `json:"old_name"`
+	NewName string `json:"new_name"`
+}
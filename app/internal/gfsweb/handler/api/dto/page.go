@@ -0,0 +1,14 @@
+package dto
+
+//PageOutput is a marshalable envelope that should be used to represent a single
+//page of entities outside of the API.
+type PageOutput struct {
+	//Data is the transformed entities in the page.
+	Data interface{} `json:"data"`
+
+	//Total is the total number of entities in all pages.
+	Total int `json:"total"`
+
+	//Next is the link to the next page or nil if this is the last page.
+	Next *string `json:"next"`
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
 )
 
@@ -24,18 +25,27 @@ func Users(v interface{}) interface{} {
 func User(v interface{}) interface{} {
 	user := v.(*user.User)
 
-	clientIds := user.ClientIds
+	clientIds := user.ClientIds()
 	if clientIds == nil {
 		clientIds = []data.Id{}
 	}
 
+	memberships := make([]*MembershipOutput, len(user.Memberships))
+	for i, m := range user.Memberships {
+		memberships[i] = &MembershipOutput{
+			ClientId: m.ClientId,
+			Role:     m.Role,
+		}
+	}
+
 	return &UserOutput{
-		Id:        user.Id,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-		Enabled:   user.Enabled,
-		ClientIds: clientIds,
+		Id:          user.Id,
+		Email:       user.Email,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+		Enabled:     user.Enabled,
+		ClientIds:   clientIds,
+		Memberships: memberships,
 	}
 }
 
@@ -47,21 +57,124 @@ type UserOutput struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	Enabled   bool      `json:"enabled"`
-	ClientIds []data.Id `json:"client_ids"`
+
+	//ClientIds is kept alongside Memberships for backwards compatibility.
+	ClientIds   []data.Id           `json:"client_ids"`
+	Memberships []*MembershipOutput `json:"memberships"`
+}
+
+//MembershipOutput is a marshalable type that should be used to represent a
+//User's Membership of a Client outside of the API.
+type MembershipOutput struct {
+	ClientId data.Id   `json:"client_id"`
+	Role     user.Role `json:"role"`
+}
+
+//UserWithClientsView is a composite view of a User and its Clients, which are
+//embedded in its output.
+type UserWithClientsView struct {
+	//User is the User being viewed.
+	User *user.User
+
+	//Clients are the Clients of User in the order of its ClientIds.
+	Clients []*client.Client
+}
+
+//UsersWithClients transforms a []*UserWithClientsView to a []*UserWithClientsOutput.
+//It delegates to UserWithClients.
+func UsersWithClients(v interface{}) interface{} {
+	views := v.([]*UserWithClientsView)
+
+	result := make([]interface{}, len(views))
+	for i, view := range views {
+		result[i] = UserWithClients(view)
+	}
+
+	return result
+}
+
+//UserWithClients transforms a *UserWithClientsView to a *UserWithClientsOutput.
+//It delegates to User and Clients.
+func UserWithClients(v interface{}) interface{} {
+	view := v.(*UserWithClientsView)
+
+	return &UserWithClientsOutput{
+		UserOutput: User(view.User).(*UserOutput),
+		Clients:    Clients(view.Clients).([]interface{}),
+	}
+}
+
+//UserWithClientsOutput is a marshalable type that should be used to represent
+//a User with its Clients embedded outside of the API.
+type UserWithClientsOutput struct {
+	*UserOutput
+	Clients []interface{} `json:"clients"`
 }
 
 //CreateUser is a form type that should be used for incoming create user requests
 //to the API.
+//See UserMemberships for how ClientIds and Memberships are combined.
 type CreateUser struct {
-	Email     string    `json:"email" valid:"required,email"`
-	Enabled   bool      `json:"enabled"`
-	ClientIds []data.Id `json"client_ids"`
+	Email       string       `json:"email" valid:"required,email"`
+	Enabled     bool         `json:"enabled"`
+	ClientIds   []data.Id    `json:"client_ids"`
+	Memberships []Membership `json:"memberships"`
 }
 
 //UpdateUser is a form type that should be used for incoming udpate user requests
 //to the API.
+//See UserMemberships for how ClientIds and Memberships are combined.
 type UpdateUser struct {
-	Email     *string    `json:"email" valid:"omitempty,email"`
-	Enabled   *bool      `json:"enabled"`
-	ClientIds *[]data.Id `json:"client_ids"`
+	Email       *string       `json:"email" valid:"omitempty,email"`
+	Enabled     *bool         `json:"enabled"`
+	ClientIds   *[]data.Id    `json:"client_ids"`
+	Memberships *[]Membership `json:"memberships"`
+}
+
+//Membership is a form type that should be used for the Memberships of incoming
+//user requests to the API.
+//An empty Role is chosen by the command the Membership is used in.
+type Membership struct {
+	ClientId data.Id   `json:"client_id"`
+	Role     user.Role `json:"role"`
+}
+
+//UserMemberships combines clientIds and memberships into the Memberships of a
+//User command.
+//Memberships take precedence, and each of clientIds without one is added with an
+//empty Role.
+//The result is nil if both are nil.
+func UserMemberships(clientIds []data.Id, memberships []Membership) []user.Membership {
+	if clientIds == nil && memberships == nil {
+		return nil
+	}
+
+	result := make([]user.Membership, 0, len(clientIds)+len(memberships))
+	seen := make(map[data.Id]bool, len(memberships))
+
+	for _, m := range memberships {
+		seen[m.ClientId] = true
+		result = append(result, user.Membership{
+			ClientId: m.ClientId,
+			Role:     m.Role,
+		})
+	}
+
+	for _, id := range clientIds {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, user.Membership{
+			ClientId: id,
+		})
+	}
+
+	return result
+}
+
+//UserClient is a form type that should be used for the optional body of incoming
+//requests to add a single Client to a User.
+type UserClient struct {
+	Role user.Role `json:"role"`
 }
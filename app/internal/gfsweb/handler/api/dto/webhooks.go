@@ -0,0 +1,124 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
+)
+
+//Webhooks transforms a []*webhook.Webhook to a []*WebhookOutput.
+//It delegates to Webhook.
+func Webhooks(v interface{}) interface{} {
+	webhooks := v.([]*webhook.Webhook)
+
+	result := make([]interface{}, len(webhooks))
+	for i, w := range webhooks {
+		result[i] = Webhook(w)
+	}
+
+	return result
+}
+
+//Webhook transforms a single *webhook.Webhook to a *WebhookOutput.
+//The Webhook's Secret is not included.
+func Webhook(v interface{}) interface{} {
+	w := v.(*webhook.Webhook)
+
+	events := w.Events
+	if events == nil {
+		events = []string{}
+	}
+
+	return &WebhookOutput{
+		Id:        w.Id,
+		URL:       w.URL,
+		Events:    events,
+		Enabled:   w.Enabled,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+//WebhookOutput is a marshalable type that should be used for external
+//representations of webhook.Webhook(s) outside the API.
+//Secret is only set in the response to creating a Webhook.
+type WebhookOutput struct {
+	Id        data.Id   `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Enabled   bool      `json:"enabled"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+//Deliveries transforms a []*webhook.Delivery to a []*DeliveryOutput.
+//It delegates to Delivery.
+func Deliveries(v interface{}) interface{} {
+	deliveries := v.([]*webhook.Delivery)
+
+	result := make([]interface{}, len(deliveries))
+	for i, d := range deliveries {
+		result[i] = Delivery(d)
+	}
+
+	return result
+}
+
+//Delivery transforms a single *webhook.Delivery to a *DeliveryOutput.
+func Delivery(v interface{}) interface{} {
+	d := v.(*webhook.Delivery)
+
+	return &DeliveryOutput{
+		Id:             d.Id,
+		WebhookId:      d.WebhookId,
+		Event:          d.Event,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastAttemptAt:  d.LastAttemptAt,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+}
+
+//DeliveryOutput is a marshalable type that should be used for external
+//representations of webhook.Delivery(s) outside the API.
+type DeliveryOutput struct {
+	Id             data.Id         `json:"id"`
+	WebhookId      data.Id         `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at"`
+	ResponseStatus int             `json:"response_status"`
+	LastError      string          `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+//CreateWebhook is a form type that should be used for incoming create webhook
+//requests to the API.
+//A missing Secret is generated, and a missing Enabled defaults to true.
+type CreateWebhook struct {
+	URL     string   `json:"url" valid:"required"`
+	Events  []string `json:"events"`
+	Secret  string   `json:"secret"`
+	Enabled *bool    `json:"enabled"`
+}
+
+//UpdateWebhook is a form type that should be used for incoming update webhook
+//requests to the API.
+type UpdateWebhook struct {
+	URL     *string   `json:"url" valid:"omitempty,required"`
+	Events  *[]string `json:"events"`
+	Secret  *string   `json:"secret" valid:"omitempty,required"`
+	Enabled *bool     `json:"enabled"`
+}
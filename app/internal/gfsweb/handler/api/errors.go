@@ -0,0 +1,207 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/validate"
+)
+
+//Error codes are stable, machine readable values sent with every error response.
+//Unlike error messages, they do not change and can be relied upon by integrations.
+const (
+	//ErrorCodeBadRequest indicates that the request could not be understood.
+	ErrorCodeBadRequest = "bad_request"
+
+	//ErrorCodeUnauthorized indicates that a request does not have a valid api key.
+	ErrorCodeUnauthorized = "unauthorized"
+
+	//ErrorCodeNotFound indicates that a requested entity does not exist.
+	ErrorCodeNotFound = "not_found"
+
+	//ErrorCodeConflict indicates that an entity could not be stored because a
+	//unique field's value is already taken.
+	ErrorCodeConflict = "conflict"
+
+	//ErrorCodeValidationFailed indicates that a form does not satisfy its
+	//validation rules.
+	//The response lists each invalid field.
+	ErrorCodeValidationFailed = "validation_failed"
+
+	//ErrorCodeUnknownClientIds indicates that a User references Clients that do
+	//not exist.
+	//The response lists the unknown client ids.
+	ErrorCodeUnknownClientIds = "unknown_client_ids"
+
+	//ErrorCodeUnknownClientNames indicates that an imported User references Clients
+	//by names that do not exist.
+	//The response lists the unknown client names.
+	ErrorCodeUnknownClientNames = "unknown_client_names"
+
+	//ErrorCodeInvalidRole indicates that a User's role for a Client is not one of
+	//the known roles.
+	ErrorCodeInvalidRole = "invalid_role"
+
+	//ErrorCodePreconditionFailed indicates that an entity was not changed because
+	//it is not at the version given in the If-Match header.
+	ErrorCodePreconditionFailed = "precondition_failed"
+
+	//ErrorCodeInternal indicates that the request failed for a reason that is
+	//not the client's fault.
+	ErrorCodeInternal = "internal_error"
+)
+
+//ContentTypeProblem is the media type of error responses.
+const ContentTypeProblem = "application/problem+json"
+
+//internalErrorDetail is the detail sent for internal errors when their actual
+//details are hidden.
+const internalErrorDetail = "The request could not be completed. Refer to the request_id when reporting this."
+
+//errorStatusCode returns the http status and error code that should be sent for err.
+//Known domain errors determine their own status.
+//DefaultStatus is returned for all other errors.
+func errorStatusCode(err error, defaultStatus int) (int, string) {
+	switch {
+	case err == ErrMissingAPIKey,
+		err == ErrInvalidAPIKey:
+		return http.StatusUnauthorized, ErrorCodeUnauthorized
+	case data.IsNotFound(err):
+		return http.StatusNotFound, ErrorCodeNotFound
+	case data.IsConflict(err):
+		return http.StatusConflict, ErrorCodeConflict
+	case validate.IsInvalid(err):
+		return http.StatusUnprocessableEntity, ErrorCodeValidationFailed
+	case user.IsUnknownClientIds(err):
+		return http.StatusUnprocessableEntity, ErrorCodeUnknownClientIds
+	case IsUnknownClientNames(err):
+		return http.StatusUnprocessableEntity, ErrorCodeUnknownClientNames
+	case err == user.ErrInvalidRole:
+		return http.StatusUnprocessableEntity, ErrorCodeInvalidRole
+	case data.IsVersionMismatch(err):
+		return http.StatusPreconditionFailed, ErrorCodePreconditionFailed
+	case err == data.ErrWrongVersion,
+		err == data.ErrInvalidCursor,
+		err == data.ErrInvalidPageLimit,
+		err == webhook.ErrInvalidURL,
+		err == apikey.ErrEmptyName,
+		err == ErrBadRequestRouteParameter,
+		err == ErrBadRequestQueryParameter,
+		err == ErrUnknownBatchMode,
+		err == ErrUnknownBatchOp,
+		err == ErrBatchTooLarge,
+		err == ErrImportMissingColumn,
+		err == ErrImportTooLarge,
+		err == ErrImportInvalidEnabled:
+		return http.StatusBadRequest, ErrorCodeBadRequest
+	}
+
+	if defaultStatus >= http.StatusInternalServerError {
+		return defaultStatus, ErrorCodeInternal
+	}
+	return defaultStatus, ErrorCodeBadRequest
+}
+
+//sendError is a helper method to send failure responses as an RFC 7807
+//problem built from err.
+//The status and error code sent are determined by errorStatusCode(err, status).
+//
+//The details of internal errors are logged and only sent if a.ExposeInternalErrors
+//is true.
+//The problem's request id is the X-Request-Id header already set on w.
+func (a *API) sendError(w http.ResponseWriter, err error, status int) {
+	a.sendProblem(w, a.newProblem(w, err, status))
+}
+
+//newProblem is a helper method to build the problem sent for err as described
+//by sendError.
+func (a *API) newProblem(w http.ResponseWriter, err error, status int) *problem {
+	status, code := errorStatusCode(err, status)
+
+	resp := &problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    err.Error(),
+		Code:      code,
+		RequestId: w.Header().Get(headerRequestId),
+	}
+
+	if code == ErrorCodeInternal {
+		log.Printf("api: request %s: %v", resp.RequestId, err)
+		if !a.ExposeInternalErrors {
+			resp.Detail = internalErrorDetail
+		}
+	}
+
+	if conflictErr, ok := err.(*data.ConflictError); ok {
+		resp.Field = conflictErr.Field
+	}
+	if unknownErr, ok := err.(*user.UnknownClientIdsError); ok {
+		resp.ClientIds = unknownErr.ClientIds
+	}
+	if unknownErr, ok := err.(*UnknownClientNamesError); ok {
+		resp.ClientNames = unknownErr.ClientNames
+	}
+	if validateErr, ok := err.(*validate.Error); ok {
+		for _, f := range validateErr.Fields {
+			resp.Errors = append(resp.Errors, problemFieldError{
+				Field:   f.Field,
+				Rule:    f.Rule,
+				Message: f.Message,
+			})
+		}
+	}
+
+	return resp
+}
+
+//sendProblem is a helper method to send resp as the response with its status.
+func (a *API) sendProblem(w http.ResponseWriter, resp *problem) {
+	body, err := encodeResponse(resp)
+	if err != nil {
+		//There is nothing else we can send if an error cannot be encoded.
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(resp.Status)
+	w.Write(body)
+}
+
+//notFound sends a not found problem for requests that do not match a route.
+func (a *API) notFound(w http.ResponseWriter, r *http.Request) {
+	a.sendError(w, data.ErrNotFound, http.StatusNotFound)
+}
+
+//problem is an RFC 7807 problem details object that knows how to marshal an
+//error response.
+//Field, Errors, ClientIds, and ClientNames are extensions for the errors that have them, and
+//Index is the extension for the failed operation of a batch.
+type problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail"`
+	Code      string `json:"code"`
+	RequestId string `json:"request_id,omitempty"`
+
+	Field       string              `json:"field,omitempty"`
+	Errors      []problemFieldError `json:"errors,omitempty"`
+	ClientIds   []data.Id           `json:"client_ids,omitempty"`
+	ClientNames []string            `json:"client_names,omitempty"`
+	Index       *int                `json:"index,omitempty"`
+}
+
+//problemFieldError is a simple type that knows how to marshal a single invalid
+//field of a form.
+type problemFieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
+)
+
+const (
+	headerETag    = "ETag"
+	headerIfMatch = "If-Match"
+)
+
+//etag returns the entity tag that represents version.
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+//setETag is a helper function to set the ETag header from entity's version if
+//entity is a versioned domain type.
+func setETag(w http.ResponseWriter, entity interface{}) {
+	if tag, ok := entityETag(entity); ok {
+		w.Header().Set(headerETag, tag)
+	}
+}
+
+//entityETag returns the entity tag of entity's version and whether or not
+//entity is a versioned domain type.
+func entityETag(entity interface{}) (string, bool) {
+	var version int
+
+	switch entity := entity.(type) {
+	case *user.User:
+		version = entity.Version
+	case *client.Client:
+		version = entity.Version
+	case *webhook.Webhook:
+		version = entity.Version
+	case *apikey.Key:
+		version = entity.Version
+	default:
+		return "", false
+	}
+
+	return etag(version), true
+}
+
+//ifMatchVersion is a helper method to parse the version an entity must be at
+//from r's If-Match header.
+//The result is nil if the header is not present or is "*".
+//If false is returned, the header can never match and a response was sent.
+func (a *API) ifMatchVersion(w http.ResponseWriter, r *http.Request) (*int, bool) {
+	ifMatch := strings.TrimSpace(r.Header.Get(headerIfMatch))
+	if ifMatch == "" || ifMatch == "*" {
+		return nil, true
+	}
+
+	unquoted, err := strconv.Unquote(ifMatch)
+	if err != nil {
+		a.sendError(w, data.ErrVersionMismatch, http.StatusPreconditionFailed)
+		return nil, false
+	}
+
+	version, err := strconv.Atoi(unquoted)
+	if err != nil {
+		a.sendError(w, data.ErrVersionMismatch, http.StatusPreconditionFailed)
+		return nil, false
+	}
+
+	return &version, true
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/event/eventstream"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/dto"
+)
+
+const (
+	//pathEvents is the path of the event stream route.
+	pathEvents = "/events"
+
+	queryParamEntity      = "entity"
+	queryParamLastEventId = "last_event_id"
+
+	//headerLastEventId is sent by EventSource clients when they reconnect.
+	headerLastEventId = "Last-Event-ID"
+
+	//eventReset is the name of the server-sent event sent when a resumed stream
+	//has missed notifications, indicating entities should be retrieved again.
+	eventReset = "reset"
+
+	//eventStreamHeartbeat is how often a comment is sent to keep idle streams
+	//from being closed by proxies.
+	eventStreamHeartbeat = 15 * time.Second
+)
+
+//ErrStreamingUnsupported is a sentinel error indicating the response cannot be
+//streamed to the client.
+var ErrStreamingUnsupported = errors.New("api: streaming unsupported")
+
+//streamEvents streams user and client change notifications as server-sent events
+//until the client disconnects.
+//
+//The entity query parameter limits the stream to the given entity types and
+//may be repeated or comma separated.
+//The client_id query parameter limits the stream to the given Client and the
+//Users that belong to it.
+//
+//The Last-Event-ID header, or the last_event_id query parameter, resumes the
+//stream after that event.
+//A reset event is sent first if notifications since then are no longer
+//available.
+func (a *API) streamEvents(w http.ResponseWriter, r *http.Request) {
+	filter, ok := a.streamFilterFrom(w, r)
+	if !ok {
+		return
+	}
+
+	lastId, ok := a.lastEventIdFrom(w, r)
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		a.sendError(w, ErrStreamingUnsupported, http.StatusInternalServerError)
+		return
+	}
+
+	listener, missed, complete := a.Stream.Listen(lastId, filter)
+	defer listener.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if !complete {
+		writeServerSentEvent(w, strconv.FormatUint(listener.Since(), 10), eventReset, []byte("{}"))
+	}
+	for _, n := range missed {
+		if err := writeNotification(w, n); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case n, ok := <-listener.C():
+			//The listener is closed if it fell behind or the server is shutting
+			//down, and the client should reconnect with its Last-Event-ID.
+			if !ok {
+				return
+			}
+			if err := writeNotification(w, n); err != nil {
+				return
+			}
+
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+
+		flusher.Flush()
+	}
+}
+
+//streamFilterFrom is a helper method to parse the eventstream.Filter for a
+//request's query parameters.
+//If false is returned, the parameters could not be parsed and a response was sent.
+func (a *API) streamFilterFrom(w http.ResponseWriter, r *http.Request) (eventstream.Filter, bool) {
+	filter := eventstream.Filter{}
+
+	for _, value := range r.URL.Query()[queryParamEntity] {
+		for _, entity := range strings.Split(value, ",") {
+			if entity != user.EntityName && entity != client.EntityName {
+				a.sendError(w, ErrBadRequestQueryParameter, http.StatusBadRequest)
+				return filter, false
+			}
+			filter.Entities = append(filter.Entities, entity)
+		}
+	}
+
+	clientId, ok := a.queryId(w, r, queryParamClientId)
+	filter.ClientId = clientId
+
+	return filter, ok
+}
+
+//lastEventIdFrom is a helper method to parse the id of the last event a
+//reconnecting client received.
+//The result is 0 if the client did not send one.
+//If false is returned, the id could not be parsed and a response was sent.
+func (a *API) lastEventIdFrom(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	value := r.Header.Get(headerLastEventId)
+	if value == "" {
+		value = r.URL.Query().Get(queryParamLastEventId)
+	}
+	if value == "" {
+		return 0, true
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		a.sendError(w, ErrBadRequestQueryParameter, http.StatusBadRequest)
+		return 0, false
+	}
+
+	return id, true
+}
+
+//writeNotification writes n as a server-sent event named after n's Event.
+func writeNotification(w io.Writer, n *eventstream.Notification) error {
+	output := dto.Notification(n).(*dto.NotificationOutput)
+
+	body, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+
+	return writeServerSentEvent(w, output.Id, output.Event, body)
+}
+
+//writeServerSentEvent writes a single server-sent event with id, name, and data.
+//Data must not contain newlines.
+func writeServerSentEvent(w io.Writer, id, name string, data []byte) error {
+	_, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", id, name, data)
+	return err
+}
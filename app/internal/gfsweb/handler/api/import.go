@@ -0,0 +1,361 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/dto"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/validate"
+	"github.com/gogolfing/cbus"
+)
+
+//MaxImportRows is the maximum number of rows, not including the header, allowed
+//in a single import.
+const MaxImportRows = 1000
+
+//MaxImportBytes is the maximum size of an import's request body.
+//Reading a larger body fails, which is sent as a bad request.
+const MaxImportBytes = 4 << 20
+
+const queryParamDryRun = "dry_run"
+
+//Statuses of imported rows.
+const (
+	importStatusCreated = "created"
+	importStatusUpdated = "updated"
+	importStatusFailed  = "failed"
+)
+
+var (
+	//ErrImportMissingColumn is a sentinel error indicating that an import's header
+	//does not have a required column.
+	ErrImportMissingColumn = errors.New("api: import is missing a required column")
+
+	//ErrImportTooLarge is a sentinel error indicating that an import has more than
+	//MaxImportRows rows.
+	ErrImportTooLarge = errors.New("api: too many import rows")
+
+	//ErrImportInvalidEnabled is a sentinel error indicating that an imported row's
+	//enabled value is not a boolean.
+	ErrImportInvalidEnabled = errors.New("api: import enabled must be true or false")
+
+	//errDryRun is used to roll back the transaction of a dry run import.
+	errDryRun = errors.New("api: dry run")
+)
+
+//UnknownClientNamesError is an error indicating that an imported row references
+//Clients by names that do not exist.
+type UnknownClientNamesError struct {
+	//ClientNames are the names that do not exist.
+	ClientNames []string
+}
+
+//Error is the error implementation.
+func (e *UnknownClientNamesError) Error() string {
+	return "api: unknown client names: " + strings.Join(e.ClientNames, ", ")
+}
+
+//IsUnknownClientNames returns whether or not err is an *UnknownClientNamesError.
+func IsUnknownClientNames(err error) bool {
+	_, ok := err.(*UnknownClientNamesError)
+	return ok
+}
+
+//userImportRow is a single parsed row of a User import.
+type userImportRow struct {
+	//row is the 1-based row number in the CSV, where the header is row 1.
+	row int
+
+	email string
+
+	//enabled is nil if the cell is empty or the column is not present.
+	enabled *bool
+
+	//clientNames is nil if the column is not present.
+	clientNames *[]string
+
+	//err is set if the row could not be parsed.
+	err error
+}
+
+//importUsers creates and updates Users from the rows of a CSV request body and
+//sends a dto.ImportOutput reporting the result of each row.
+//
+//The header must have an email column and may have enabled and client_names
+//columns, which makes an export a valid import.
+//Other columns are ignored.
+//A row whose email belongs to an existing User updates that User, and all other
+//rows create a new User.
+//An empty enabled cell leaves the User's enabled value unchanged, and client
+//names, separated by csvListSeparator, replace the User's Clients if the column
+//is present.
+//Cells escaped by an export are unescaped, and the body may be at most
+//MaxImportBytes.
+//
+//Each row is executed as usercmd commands through a.Bus inside of a single
+//transaction, and a failed row is only rolled back on its own.
+//If the dry_run query parameter is true, the transaction is always rolled back
+//so the report previews the import.
+func (a *API) importUsers(w http.ResponseWriter, r *http.Request) {
+	dryRun, ok := a.queryBool(w, r, queryParamDryRun)
+	if !ok {
+		return
+	}
+
+	rows, err := parseUserImport(http.MaxBytesReader(w, r.Body, MaxImportBytes))
+	if err != nil {
+		a.sendError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	output := &dto.ImportOutput{
+		DryRun: dryRun != nil && *dryRun,
+		Rows:   make([]*dto.ImportRowOutput, len(rows)),
+	}
+
+	err = a.Transactor.Transact(r.Context(), func(ctx context.Context) error {
+		clientIds, err := a.clientIdsByName(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i, row := range rows {
+			result := a.importUserRow(ctx, w, row, clientIds, output.DryRun)
+
+			switch result.Status {
+			case importStatusCreated:
+				output.Created++
+			case importStatusUpdated:
+				output.Updated++
+			default:
+				output.Failed++
+			}
+
+			output.Rows[i] = result
+		}
+
+		if output.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && err != errDryRun {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	a.sendResponse(w, output, http.StatusOK)
+}
+
+//importUserRow is a helper method to execute the command for row and return
+//its result.
+//The result does not have the Id of the User if dryRun is true since it will
+//not exist.
+func (a *API) importUserRow(ctx context.Context, w http.ResponseWriter, row *userImportRow, clientIds map[string]data.Id, dryRun bool) *dto.ImportRowOutput {
+	result := &dto.ImportRowOutput{
+		Row:   row.row,
+		Email: row.email,
+	}
+
+	command, status, err := a.userImportCommand(ctx, row, clientIds)
+
+	var u interface{}
+	if err == nil {
+		u, err = a.Bus.ExecuteContext(ctx, command)
+	}
+
+	if err != nil {
+		result.Status = importStatusFailed
+		result.Error = a.newProblem(w, err, http.StatusInternalServerError)
+		return result
+	}
+
+	result.Status = status
+	if !dryRun {
+		result.Id = &u.(*user.User).Id
+	}
+
+	return result
+}
+
+//userImportCommand is a helper method to return the usercmd command for row and
+//the import status of row if the command succeeds.
+//The User is updated if one exists with row's email, and created otherwise.
+//
+//The command's form is validated the same as it is for requests.
+func (a *API) userImportCommand(ctx context.Context, row *userImportRow, clientIds map[string]data.Id) (cbus.Command, string, error) {
+	if row.err != nil {
+		return nil, "", row.err
+	}
+
+	var ids *[]data.Id
+	if row.clientNames != nil {
+		resolved, err := resolveClientNames(*row.clientNames, clientIds)
+		if err != nil {
+			return nil, "", err
+		}
+		ids = &resolved
+	}
+
+	existing, err := a.Users.GetEmail(ctx, row.email)
+	if err != nil && !data.IsNotFound(err) {
+		return nil, "", err
+	}
+
+	if err != nil {
+		f := &dto.CreateUser{
+			Email:   row.email,
+			Enabled: row.enabled != nil && *row.enabled,
+		}
+		if ids != nil {
+			f.ClientIds = *ids
+		}
+
+		return createUserCommand(f), importStatusCreated, validate.Struct(f)
+	}
+
+	f := &dto.UpdateUser{
+		Enabled:   row.enabled,
+		ClientIds: ids,
+	}
+
+	return updateUserCommand(existing.Id, nil, f), importStatusUpdated, validate.Struct(f)
+}
+
+//clientIdsByName is a helper method to retrieve the Ids of all Clients keyed by
+//their names.
+func (a *API) clientIdsByName(ctx context.Context) (map[string]data.Id, error) {
+	clients, err := a.Clients.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]data.Id, len(clients))
+	for _, c := range clients {
+		result[c.Name] = c.Id
+	}
+
+	return result, nil
+}
+
+//resolveClientNames returns the Id in clientIds of each of names, without
+//duplicates.
+//An *UnknownClientNamesError listing the names without Ids is returned otherwise.
+func resolveClientNames(names []string, clientIds map[string]data.Id) ([]data.Id, error) {
+	result := make([]data.Id, 0, len(names))
+	var unknown []string
+
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		id, ok := clientIds[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		result = append(result, id)
+	}
+
+	if len(unknown) > 0 {
+		return nil, &UnknownClientNamesError{
+			ClientNames: unknown,
+		}
+	}
+	return result, nil
+}
+
+//parseUserImport parses the rows of a User import from the CSV in body.
+//Errors in a single row are set on that row, and an error is only returned if
+//body is not valid CSV, its header is invalid, or it has too many rows.
+func parseUserImport(body io.Reader) ([]*userImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrImportMissingColumn
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		if i == 0 {
+			//Spreadsheet applications often start UTF-8 files with a byte order mark.
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	if _, ok := columns[csvColumnEmail]; !ok {
+		return nil, ErrImportMissingColumn
+	}
+
+	rows := []*userImportRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rows) == MaxImportRows {
+			return nil, ErrImportTooLarge
+		}
+
+		rows = append(rows, parseUserImportRow(len(rows)+2, record, columns))
+	}
+
+	return rows, nil
+}
+
+//parseUserImportRow parses record, the row-th row of an import, whose cells are
+//indexed by columns.
+func parseUserImportRow(row int, record []string, columns map[string]int) *userImportRow {
+	cell := func(column string) (string, bool) {
+		i, ok := columns[column]
+		if !ok {
+			return "", false
+		}
+		if i >= len(record) {
+			return "", true
+		}
+		return csvUncell(strings.TrimSpace(record[i])), true
+	}
+
+	result := &userImportRow{
+		row: row,
+	}
+	result.email, _ = cell(csvColumnEmail)
+
+	if enabled, _ := cell(csvColumnEnabled); enabled != "" {
+		b, err := strconv.ParseBool(enabled)
+		if err != nil {
+			result.err = ErrImportInvalidEnabled
+			return result
+		}
+		result.enabled = &b
+	}
+
+	if clientNames, ok := cell(csvColumnClientNames); ok {
+		names := splitCSVList(clientNames)
+		result.clientNames = &names
+	}
+
+	return result
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/dto"
+)
+
+//queryParamInclude is a comma separated list of related entities to embed in a
+//response.
+const queryParamInclude = "include"
+
+//includeClients is the include value that embeds Users' Clients.
+const includeClients = "clients"
+
+//includesClients is a helper method to parse whether or not r's include query
+//parameter requests Users' Clients.
+//If false is returned, the parameter requests an unknown inclusion and a response
+//was sent.
+func (a *API) includesClients(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	result := false
+
+	for _, name := range strings.Split(r.URL.Query().Get(queryParamInclude), ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case includeClients:
+			result = true
+		default:
+			a.sendError(w, ErrBadRequestQueryParameter, http.StatusBadRequest)
+			return false, false
+		}
+	}
+
+	return result, true
+}
+
+//usersWithClients is a helper method to return a dto.UserWithClientsView for each
+//of users.
+//The Clients of all users are retrieved with a single query.
+func (a *API) usersWithClients(ctx context.Context, users []*user.User) ([]*dto.UserWithClientsView, error) {
+	ids := []data.Id{}
+	seen := map[data.Id]bool{}
+	for _, u := range users {
+		for _, id := range u.ClientIds() {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	clients, err := a.Clients.ListByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[data.Id]*client.Client, len(clients))
+	for _, c := range clients {
+		byId[c.Id] = c
+	}
+
+	result := make([]*dto.UserWithClientsView, len(users))
+	for i, u := range users {
+		view := &dto.UserWithClientsView{
+			User:    u,
+			Clients: make([]*client.Client, 0, len(u.Memberships)),
+		}
+		for _, m := range u.Memberships {
+			if c, ok := byId[m.ClientId]; ok {
+				view.Clients = append(view.Clients, c)
+			}
+		}
+		result[i] = view
+	}
+
+	return result, nil
+}
+
+//sendUserPage is a helper method to send a page of users with sendPage.
+//Their Clients are embedded if withClients is true.
+func (a *API) sendUserPage(w http.ResponseWriter, r *http.Request, users []*user.User, withClients bool, req data.PageRequest, page data.Page) {
+	if !withClients {
+		a.sendPage(w, r, users, req, page)
+		return
+	}
+
+	views, err := a.usersWithClients(r.Context(), users)
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	a.sendPage(w, r, views, req, page)
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/dto"
+)
+
+const (
+	queryParamLimit  = "limit"
+	queryParamCursor = "cursor"
+)
+
+//pageRequestFrom is a helper method to parse a data.PageRequest from r's query
+//parameters.
+//If false is returned, the parameters were invalid and a response was sent.
+func (a *API) pageRequestFrom(w http.ResponseWriter, r *http.Request) (data.PageRequest, bool) {
+	query := r.URL.Query()
+
+	page := data.PageRequest{
+		Limit:  data.DefaultPageLimit,
+		Cursor: query.Get(queryParamCursor),
+	}
+
+	if limit := query.Get(queryParamLimit); limit != "" {
+		var err error
+		page.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			a.sendError(w, data.ErrInvalidPageLimit, http.StatusBadRequest)
+			return page, false
+		}
+	}
+
+	if err := page.Validate(); err != nil {
+		a.sendError(w, err, http.StatusBadRequest)
+		return page, false
+	}
+
+	return page, true
+}
+
+//sendPage is a helper method to send a page of entities inside of a
+//dto.PageOutput envelope.
+//The envelope's next link is r's URL with the cursor parameter replaced.
+//
+//The ETag header is a hash of the response body, and a 304 is sent instead if
+//the request's If-None-Match header matches it.
+func (a *API) sendPage(w http.ResponseWriter, r *http.Request, entities interface{}, req data.PageRequest, page data.Page) {
+	transformed, err := dto.Transform(entities)
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	output := &dto.PageOutput{
+		Data:  transformed,
+		Total: page.Total,
+	}
+
+	if page.Next != "" {
+		next := *r.URL
+		query := next.Query()
+		query.Set(queryParamLimit, strconv.Itoa(req.Limit))
+		query.Set(queryParamCursor, page.Next)
+		next.RawQuery = query.Encode()
+
+		link := next.RequestURI()
+		output.Next = &link
+	}
+
+	a.sendHashedResponse(w, r, output)
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//ErrBadRequestQueryParameter is a sentinel error indicating a query parameter
+//could not be parsed.
+var ErrBadRequestQueryParameter = errors.New("api: bad request query parameter")
+
+//queryBool is a helper method to parse an optional bool query parameter.
+//The result is nil if the parameter is not present.
+//If false is returned, the parameter could not be parsed and a response was sent.
+func (a *API) queryBool(w http.ResponseWriter, r *http.Request, name string) (*bool, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, true
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		a.sendError(w, ErrBadRequestQueryParameter, http.StatusBadRequest)
+		return nil, false
+	}
+
+	return &b, true
+}
+
+//queryId is a helper method to parse an optional data.Id query parameter.
+//The result is nil if the parameter is not present.
+//If false is returned, the parameter could not be parsed and a response was sent.
+func (a *API) queryId(w http.ResponseWriter, r *http.Request, name string) (*data.Id, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, true
+	}
+
+	id, err := data.ParseId(value)
+	if err != nil {
+		a.sendError(w, ErrBadRequestQueryParameter, http.StatusBadRequest)
+		return nil, false
+	}
+
+	return &id, true
+}
+
+//queryTime is a helper method to parse an optional RFC 3339 time query parameter.
+//The result is nil if the parameter is not present.
+//If false is returned, the parameter could not be parsed and a response was sent.
+func (a *API) queryTime(w http.ResponseWriter, r *http.Request, name string) (*time.Time, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, true
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		a.sendError(w, ErrBadRequestQueryParameter, http.StatusBadRequest)
+		return nil, false
+	}
+
+	return &t, true
+}
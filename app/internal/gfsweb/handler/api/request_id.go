@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+)
+
+//headerRequestId is the header that carries a request's id in both the request
+//and the response.
+const headerRequestId = "X-Request-Id"
+
+//maxRequestIdLength is the longest request id accepted from a client.
+const maxRequestIdLength = 128
+
+//requestIdKey is the context key for a request's id.
+type requestIdKey struct{}
+
+//RequestIdFromContext returns the id of the request being served with ctx, or
+//the empty string if there is none.
+func RequestIdFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
+
+//withRequestId returns an http.Handler that identifies each request with the
+//X-Request-Id header sent by the client, or a new id if there is not a valid one.
+//The id is set in the response's X-Request-Id header and in the request's context.
+func withRequestId(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(headerRequestId)
+		if !validRequestId(id) {
+			newId, err := data.NewId()
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			id = newId.String()
+		}
+
+		w.Header().Set(headerRequestId, id)
+
+		ctx := context.WithValue(r.Context(), requestIdKey{}, id)
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+//validRequestId returns whether or not id can be used as a request id.
+//Only short values of letters, digits, and the punctuation common in ids are
+//accepted so that client values are safe to log and echo back.
+func validRequestId(id string) bool {
+	if id == "" || len(id) > maxRequestIdLength {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
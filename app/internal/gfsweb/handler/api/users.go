@@ -3,34 +3,146 @@ package api
 import (
 	"net/http"
 
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user/usercmd"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/dto"
+	"github.com/gogolfing/cbus"
 )
 
 const (
 	routeParamUserId = "user_id"
+
+	queryParamEnabled      = "enabled"
+	queryParamClientId     = "client_id"
+	queryParamEmail        = "email"
+	queryParamCreatedAfter = "created_after"
 )
 
 //getUser attempts to retrieve and send a single User.
+//A 304 is sent instead if the request's conditional headers match the User.
+//
+//The User's Clients are embedded if the include query parameter has clients.
+//The ETag then represents the whole response since the Clients can change
+//without the User's version changing.
 func (a *API) getUser(w http.ResponseWriter, r *http.Request) {
-	user, ok := a.getUserEntity(w, r)
+	withClients, ok := a.includesClients(w, r)
+	if !ok {
+		return
+	}
+
+	u, ok := a.getUserEntity(w, r)
 	if !ok {
 		return
 	}
 
-	a.sendData(w, user, http.StatusOK)
+	if withClients {
+		views, err := a.usersWithClients(r.Context(), []*user.User{u})
+		if err != nil {
+			a.sendError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		transformed, err := dto.Transform(views[0])
+		if err != nil {
+			a.sendError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		a.sendHashedResponse(w, r, transformed)
+		return
+	}
+
+	setETag(w, u)
+	setLastModified(w, u)
+	if a.sendNotModified(w, r) {
+		return
+	}
+
+	a.sendData(w, u, http.StatusOK)
 }
 
-//listUsers attempts to retrieve and send all Users.
+//listUsers attempts to retrieve and send a single page of Users that match
+//the criteria in the query parameters.
+//
+//The email query parameter looks up a single User by its Email, and the Users'
+//Clients are embedded if the include query parameter has clients.
 func (a *API) listUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := a.Users.List(r.Context())
+	req, ok := a.pageRequestFrom(w, r)
+	if !ok {
+		return
+	}
+
+	withClients, ok := a.includesClients(w, r)
+	if !ok {
+		return
+	}
+
+	criteria, ok := a.userCriteriaFrom(w, r)
+	if !ok {
+		return
+	}
+
+	if email := r.URL.Query().Get(queryParamEmail); email != "" {
+		a.listUsersByEmail(w, r, email, criteria, withClients, req)
+		return
+	}
+
+	users, page, err := a.Users.ListPage(r.Context(), criteria, req)
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	a.sendUserPage(w, r, users, withClients, req, page)
+}
+
+//listUsersByEmail is a helper method to send the page of Users containing the
+//User with email if it exists and matches criteria.
+func (a *API) listUsersByEmail(w http.ResponseWriter, r *http.Request, email string, criteria user.Criteria, withClients bool, req data.PageRequest) {
+	after, err := data.DecodeCursor(req.Cursor)
 	if err != nil {
+		a.sendError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	users := []*user.User{}
+	page := data.Page{}
+
+	u, err := a.Users.GetEmail(r.Context(), email)
+	if err != nil && !data.IsNotFound(err) {
 		a.sendError(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	a.sendData(w, users, http.StatusOK)
+	if err == nil && criteria.Matches(u) {
+		page.Total = 1
+		if u.Email > after {
+			users = append(users, u)
+		}
+	}
+
+	a.sendUserPage(w, r, users, withClients, req, page)
+}
+
+//userCriteriaFrom is a helper method to parse a user.Criteria from r's query
+//parameters.
+//If false is returned, the parameters were invalid and a response was sent.
+func (a *API) userCriteriaFrom(w http.ResponseWriter, r *http.Request) (user.Criteria, bool) {
+	criteria := user.Criteria{}
+	ok := false
+
+	if criteria.Enabled, ok = a.queryBool(w, r, queryParamEnabled); !ok {
+		return criteria, false
+	}
+	if criteria.ClientId, ok = a.queryId(w, r, queryParamClientId); !ok {
+		return criteria, false
+	}
+	if criteria.CreatedAfter, ok = a.queryTime(w, r, queryParamCreatedAfter); !ok {
+		return criteria, false
+	}
+
+	return criteria, true
 }
 
 //createUser attempts to create a new User from a dto.CreateUser form and
@@ -42,46 +154,213 @@ func (a *API) createUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	command := &usercmd.CreateUserCommand{
-		Email:     f.Email,
-		Enabled:   f.Enabled,
-		ClientIds: f.ClientIds,
-	}
-
-	user, err := a.Bus.ExecuteContext(r.Context(), command)
+	user, err := a.Bus.ExecuteContext(r.Context(), createUserCommand(f))
 	a.userCommandResponse(w, user, err, http.StatusCreated)
 }
 
 //updateUser attempts to update an existing User from a dto.UpdateUser form
 //and executing a usercmd.UpdateUserCommand.
+//
+//The If-Match header, if present, must match the User's current ETag.
 func (a *API) updateUser(w http.ResponseWriter, r *http.Request) {
 	f := &dto.UpdateUser{}
 
-	id, _ := a.idFrom(w, r, routeParamUserId)
+	id, ok := a.idFrom(w, r, routeParamUserId)
+	if !ok {
+		return
+	}
+
+	version, ok := a.ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
 
 	if ok := a.parseForm(w, r, f); !ok {
 		return
 	}
 
-	command := &usercmd.UpdateUserCommand{
-		Id:        id,
-		Email:     f.Email,
-		Enabled:   f.Enabled,
-		ClientIds: f.ClientIds,
+	user, err := a.Bus.ExecuteContext(r.Context(), updateUserCommand(id, version, f))
+	a.userCommandResponse(w, user, err, http.StatusOK)
+}
+
+//createUserCommand returns the usercmd.CreateUserCommand for f.
+func createUserCommand(f *dto.CreateUser) *usercmd.CreateUserCommand {
+	return &usercmd.CreateUserCommand{
+		Email:       f.Email,
+		Enabled:     f.Enabled,
+		Memberships: dto.UserMemberships(f.ClientIds, f.Memberships),
+	}
+}
+
+//updateUserCommand returns the usercmd.UpdateUserCommand for f that updates the
+//User with id if it is at version.
+func updateUserCommand(id data.Id, version *int, f *dto.UpdateUser) *usercmd.UpdateUserCommand {
+	var memberships *[]user.Membership
+	if f.ClientIds != nil || f.Memberships != nil {
+		var clientIds []data.Id
+		if f.ClientIds != nil {
+			clientIds = *f.ClientIds
+		}
+		var formMemberships []dto.Membership
+		if f.Memberships != nil {
+			formMemberships = *f.Memberships
+		}
+
+		combined := dto.UserMemberships(clientIds, formMemberships)
+		memberships = &combined
+	}
+
+	return &usercmd.UpdateUserCommand{
+		Id:          id,
+		Email:       f.Email,
+		Enabled:     f.Enabled,
+		Memberships: memberships,
+		Version:     version,
+	}
+}
+
+//batchUsers attempts to execute the create and update User operations of a
+//dto.Batch form.
+//See batch.
+func (a *API) batchUsers(w http.ResponseWriter, r *http.Request) {
+	a.batch(w, r, userBatchCommand)
+}
+
+//userBatchCommand returns the user command for op, which is either a dto.CreateUser
+//or a dto.UpdateUser form depending on op's Op.
+func userBatchCommand(op *dto.BatchOperation) (cbus.Command, int, error) {
+	switch op.Op {
+	case dto.BatchOpCreate:
+		f := &dto.CreateUser{}
+		if err := parseBatchData(op, f); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		return createUserCommand(f), http.StatusCreated, nil
+
+	case dto.BatchOpUpdate:
+		id, err := batchOperationId(op)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		f := &dto.UpdateUser{}
+		if err := parseBatchData(op, f); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		return updateUserCommand(id, op.Version, f), http.StatusOK, nil
+	}
+
+	return nil, http.StatusBadRequest, ErrUnknownBatchOp
+}
+
+//deleteUser attempts to delete an existing User from the route parameter id
+//and executing a usercmd.DeleteUserCommand.
+//
+//The If-Match header, if present, must match the User's current ETag.
+func (a *API) deleteUser(w http.ResponseWriter, r *http.Request) {
+	version, ok := a.ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	//Retrieve the User to make sure it exists and to send it in the response.
+	user, ok := a.getUserEntity(w, r)
+	if !ok {
+		return
+	}
+
+	command := &usercmd.DeleteUserCommand{
+		Id:      user.Id,
+		Version: version,
+	}
+
+	_, err := a.Bus.ExecuteContext(r.Context(), command)
+	a.userCommandResponse(w, user, err, http.StatusOK)
+}
+
+//addUserClient attempts to add the Client from the route parameter client_id to
+//the User from the route parameter user_id by executing a
+//usercmd.AddUserClientCommand.
+//Adding a Client the User already has is not an error so that it can be repeated.
+//
+//The request body is an optional dto.UserClient form with the User's role for
+//the Client.
+//Without a role, the User keeps its current role, or gets the default role if
+//it does not already have the Client.
+//
+//The If-Match header, if present, must match the User's current ETag.
+func (a *API) addUserClient(w http.ResponseWriter, r *http.Request) {
+	userId, clientId, version, ok := a.userClientFrom(w, r)
+	if !ok {
+		return
+	}
+
+	f := &dto.UserClient{}
+	if ok := a.parseOptionalForm(w, r, f); !ok {
+		return
+	}
+
+	command := &usercmd.AddUserClientCommand{
+		UserId:   userId,
+		ClientId: clientId,
+		Role:     f.Role,
+		Version:  version,
 	}
 
 	user, err := a.Bus.ExecuteContext(r.Context(), command)
 	a.userCommandResponse(w, user, err, http.StatusOK)
 }
 
+//removeUserClient attempts to remove the Client from the route parameter
+//client_id from the User from the route parameter user_id by executing a
+//usercmd.RemoveUserClientCommand.
+//Removing a Client the User does not have is not an error so that it can be repeated.
+//
+//The If-Match header, if present, must match the User's current ETag.
+func (a *API) removeUserClient(w http.ResponseWriter, r *http.Request) {
+	userId, clientId, version, ok := a.userClientFrom(w, r)
+	if !ok {
+		return
+	}
+
+	command := &usercmd.RemoveUserClientCommand{
+		UserId:   userId,
+		ClientId: clientId,
+		Version:  version,
+	}
+
+	user, err := a.Bus.ExecuteContext(r.Context(), command)
+	a.userCommandResponse(w, user, err, http.StatusOK)
+}
+
+//userClientFrom is a helper method to parse the user and client ids from the
+//route parameters and the version from the If-Match header of a request to
+//change a single Client of a User.
+//If false is returned, a response was sent.
+func (a *API) userClientFrom(w http.ResponseWriter, r *http.Request) (data.Id, data.Id, *int, bool) {
+	userId, ok := a.idFrom(w, r, routeParamUserId)
+	if !ok {
+		return userId, userId, nil, false
+	}
+
+	clientId, ok := a.idFrom(w, r, routeParamClientId)
+	if !ok {
+		return userId, clientId, nil, false
+	}
+
+	version, ok := a.ifMatchVersion(w, r)
+	return userId, clientId, version, ok
+}
+
 //userCommandResponse is a helper method to send the correct response from the
 //result of a user command.
 func (a *API) userCommandResponse(w http.ResponseWriter, result interface{}, err error, okStatus int) {
 	if err != nil {
-		//We would normally inspect the error to figure out what status code to send.
+		//sendError inspects the error to figure out what status code to send.
 		a.sendError(w, err, http.StatusInternalServerError)
 		return
 	}
+
+	setETag(w, result)
 	a.sendData(w, result, okStatus)
 }
 
@@ -0,0 +1,185 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strings"
+)
+
+//TagName is the struct tag that declares a field's rules.
+const TagName = "valid"
+
+//Rules that may be declared in a valid tag.
+const (
+	RuleRequired  = "required"
+	RuleEmail     = "email"
+	RuleOmitEmpty = "omitempty"
+)
+
+//ErrUnknownRule is a sentinel error indicating that a valid tag declares a rule
+//that this package does not know.
+var ErrUnknownRule = errors.New("validate: unknown rule")
+
+//FieldError describes a single field that does not satisfy one of its rules.
+type FieldError struct {
+	//Field is the name of the field as it is sent, i.e. its json name.
+	Field string
+
+	//Rule is the rule the field does not satisfy.
+	Rule string
+
+	//Message describes the failure, e.g. "is required".
+	Message string
+}
+
+//Error is an error indicating that a value does not satisfy its rules.
+type Error struct {
+	//Fields has a FieldError for each invalid field, in declaration order.
+	Fields []FieldError
+}
+
+//Error is the error implementation.
+func (e *Error) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		messages[i] = f.Field + " " + f.Message
+	}
+	return "validate: " + strings.Join(messages, "; ")
+}
+
+//IsInvalid returns whether or not err is an *Error.
+func IsInvalid(err error) bool {
+	_, ok := err.(*Error)
+	return ok
+}
+
+//Struct evaluates the rules declared in the valid tags of the fields of v, which
+//must be a struct or a pointer to one.
+//Fields without a valid tag are not validated.
+//
+//A valid tag is a comma separated list of rules:
+//	required   the value must not be empty or only whitespace
+//	email      the value must be a single email address, e.g. "a@example.com"
+//	omitempty  a nil pointer, i.e. a field that was not sent, is not validated
+//The rules of a non-nil pointer field are evaluated on the value it points to.
+//
+//An *Error is returned if any field does not satisfy its rules, and
+//ErrUnknownRule is returned if a tag declares an unknown rule.
+func Struct(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("validate: %T is not a struct", v)
+	}
+
+	result := &Error{}
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+
+		tag := field.Tag.Get(TagName)
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		fieldErr, err := validateField(fieldName(field), rv.Field(i), strings.Split(tag, ","))
+		if err != nil {
+			return err
+		}
+		if fieldErr != nil {
+			result.Fields = append(result.Fields, *fieldErr)
+		}
+	}
+
+	if len(result.Fields) > 0 {
+		return result
+	}
+	return nil
+}
+
+//validateField evaluates rules on value and returns the first one that fails.
+func validateField(name string, value reflect.Value, rules []string) (*FieldError, error) {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() && containsRule(rules, RuleOmitEmpty) {
+			return nil, nil
+		}
+		value = reflect.Indirect(value)
+	}
+
+	for _, rule := range rules {
+		var message string
+
+		switch rule {
+		case RuleOmitEmpty:
+			continue
+		case RuleRequired:
+			if isEmpty(value) {
+				message = "is required"
+			}
+		case RuleEmail:
+			if !isEmail(value) {
+				message = "must be a valid email address"
+			}
+		default:
+			return nil, ErrUnknownRule
+		}
+
+		if message != "" {
+			return &FieldError{
+				Field:   name,
+				Rule:    rule,
+				Message: message,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+//fieldName returns the json name of field.
+func fieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+func containsRule(rules []string, rule string) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+//isEmpty returns whether or not value is invalid (a nil pointer was
+//dereferenced), its type's zero value, empty, or only whitespace.
+func isEmpty(value reflect.Value) bool {
+	if !value.IsValid() {
+		return true
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(value.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+
+	return reflect.DeepEqual(value.Interface(), reflect.Zero(value.Type()).Interface())
+}
+
+//isEmail returns whether or not value is a string of a single email address
+//without a display name.
+func isEmail(value reflect.Value) bool {
+	if !value.IsValid() || value.Kind() != reflect.String {
+		return false
+	}
+
+	address, err := mail.ParseAddress(value.String())
+	return err == nil && address.Name == "" && address.Address == value.String()
+}
@@ -0,0 +1,120 @@
+package validate_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/validate"
+)
+
+type requiredString struct {
+	Name string `json:"name" valid:"required"`
+}
+
+type requiredSlice struct {
+	Ids []string `json:"ids" valid:"required"`
+}
+
+type requiredPointer struct {
+	Enabled *bool `json:"enabled" valid:"required"`
+}
+
+type optionalEmail struct {
+	Email *string `json:"email,omitempty" valid:"omitempty,required,email"`
+}
+
+type email struct {
+	Email string `json:"email" valid:"required,email"`
+}
+
+type names struct {
+	Tagged   string `json:"tagged_name" valid:"required"`
+	Untagged string `valid:"required"`
+	Ignored  string `json:"-" valid:"required"`
+	Skipped  string `json:"skipped" valid:"-"`
+	Plain    string `json:"plain"`
+}
+
+type unknownRule struct {
+	Name string `json:"name" valid:"required,unknown"`
+}
+
+func TestStruct(t *testing.T) {
+	yes, blank, address := true, " ", "a@example.com"
+
+	tests := []struct {
+		name string
+		v    interface{}
+		want []validate.FieldError
+		err  error
+	}{
+		{"required string", requiredString{Name: "a"}, nil, nil},
+		{"required string empty", requiredString{}, required("name"), nil},
+		{"required string whitespace", &requiredString{Name: " \t\n"}, required("name"), nil},
+
+		{"required slice", requiredSlice{Ids: []string{"a"}}, nil, nil},
+		{"required slice nil", requiredSlice{}, required("ids"), nil},
+		{"required slice empty", requiredSlice{Ids: []string{}}, required("ids"), nil},
+
+		{"required pointer", requiredPointer{Enabled: &yes}, nil, nil},
+		{"required pointer nil", requiredPointer{}, required("enabled"), nil},
+
+		{"omitempty nil", optionalEmail{}, nil, nil},
+		{"omitempty non-nil", optionalEmail{Email: &address}, nil, nil},
+		{"omitempty non-nil empty", optionalEmail{Email: &blank}, required("email"), nil},
+
+		{"email", email{Email: "a@example.com"}, nil, nil},
+		{"email display name", email{Email: "A <a@example.com>"}, invalidEmail("email"), nil},
+		{"email angle brackets", email{Email: "<a@example.com>"}, invalidEmail("email"), nil},
+		{"email list", email{Email: "a@example.com, b@example.com"}, invalidEmail("email"), nil},
+		{"email missing domain", email{Email: "a"}, invalidEmail("email"), nil},
+
+		{"field names", names{}, []validate.FieldError{
+			required("tagged_name")[0],
+			required("Untagged")[0],
+			required("Ignored")[0],
+		}, nil},
+
+		{"unknown rule", unknownRule{Name: "a"}, nil, validate.ErrUnknownRule},
+	}
+
+	for _, test := range tests {
+		err := validate.Struct(test.v)
+
+		if test.err != nil {
+			if err != test.err {
+				t.Errorf("%s: error = %v, want %v", test.name, err, test.err)
+			}
+			continue
+		}
+
+		if test.want == nil {
+			if err != nil {
+				t.Errorf("%s: error = %v, want nil", test.name, err)
+			}
+			continue
+		}
+
+		if !validate.IsInvalid(err) {
+			t.Errorf("%s: error = %v, want an *Error", test.name, err)
+			continue
+		}
+		if got := err.(*validate.Error).Fields; !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Fields = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestStruct_notStruct(t *testing.T) {
+	if err := validate.Struct("a"); err == nil || validate.IsInvalid(err) {
+		t.Errorf("Struct(string) error = %v, want a non validation error", err)
+	}
+}
+
+func required(field string) []validate.FieldError {
+	return []validate.FieldError{{Field: field, Rule: validate.RuleRequired, Message: "is required"}}
+}
+
+func invalidEmail(field string) []validate.FieldError {
+	return []validate.FieldError{{Field: field, Rule: validate.RuleEmail, Message: "must be a valid email address"}}
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook/webhookcmd"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/gfsweb/handler/api/dto"
+)
+
+const (
+	routeParamWebhookId  = "webhook_id"
+	routeParamDeliveryId = "delivery_id"
+)
+
+//getWebhook retrieves and sends a single Webhook.
+func (a *API) getWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := a.getWebhookEntity(w, r)
+	if !ok {
+		return
+	}
+
+	setETag(w, webhook)
+	a.sendData(w, webhook, http.StatusOK)
+}
+
+//listWebhooks retrieves and sends a single page of Webhooks.
+func (a *API) listWebhooks(w http.ResponseWriter, r *http.Request) {
+	req, ok := a.pageRequestFrom(w, r)
+	if !ok {
+		return
+	}
+
+	webhooks, page, err := a.Webhooks.ListPage(r.Context(), req)
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	a.sendPage(w, r, webhooks, req, page)
+}
+
+//createWebhook attempts to create a new Webhook from a dto.CreateWebhook form
+//and executing a webhookcmd.CreateWebhookCommand.
+//
+//The Webhook's secret is only ever sent in this response.
+func (a *API) createWebhook(w http.ResponseWriter, r *http.Request) {
+	f := &dto.CreateWebhook{}
+	if ok := a.parseForm(w, r, f); !ok {
+		return
+	}
+
+	command := &webhookcmd.CreateWebhookCommand{
+		URL:     f.URL,
+		Events:  f.Events,
+		Secret:  f.Secret,
+		Enabled: f.Enabled == nil || *f.Enabled,
+	}
+
+	result, err := a.Bus.ExecuteContext(r.Context(), command)
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	webhook := result.(*webhook.Webhook)
+	output := dto.Webhook(webhook).(*dto.WebhookOutput)
+	output.Secret = webhook.Secret
+
+	setETag(w, webhook)
+	a.sendResponse(w, output, http.StatusCreated)
+}
+
+//updateWebhook attempts to update an existing Webhook from a dto.UpdateWebhook
+//form and executing a webhookcmd.UpdateWebhookCommand.
+//
+//The If-Match header, if present, must match the Webhook's current ETag.
+func (a *API) updateWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookId, ok := a.idFrom(w, r, routeParamWebhookId)
+	if !ok {
+		return
+	}
+
+	version, ok := a.ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	f := &dto.UpdateWebhook{}
+	if ok := a.parseForm(w, r, f); !ok {
+		return
+	}
+
+	command := &webhookcmd.UpdateWebhookCommand{
+		Id:      webhookId,
+		URL:     f.URL,
+		Events:  f.Events,
+		Secret:  f.Secret,
+		Enabled: f.Enabled,
+		Version: version,
+	}
+
+	webhook, err := a.Bus.ExecuteContext(r.Context(), command)
+	a.webhookCommandResponse(w, webhook, err, http.StatusOK)
+}
+
+//deleteWebhook attempts to delete an existing Webhook from the route parameter
+//id and executing a webhookcmd.DeleteWebhookCommand.
+//
+//The If-Match header, if present, must match the Webhook's current ETag.
+func (a *API) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	version, ok := a.ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	//Retrieve the Webhook to make sure it exists and to send it in the response.
+	webhook, ok := a.getWebhookEntity(w, r)
+	if !ok {
+		return
+	}
+
+	command := &webhookcmd.DeleteWebhookCommand{
+		Id:      webhook.Id,
+		Version: version,
+	}
+
+	_, err := a.Bus.ExecuteContext(r.Context(), command)
+	a.webhookCommandResponse(w, webhook, err, http.StatusOK)
+}
+
+//listDeliveries retrieves and sends a single page of a Webhook's Deliveries.
+func (a *API) listDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := a.getWebhookEntity(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := a.pageRequestFrom(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, page, err := a.WebhookDeliveries.ListPage(r.Context(), webhook.Id, req)
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	a.sendPage(w, r, deliveries, req, page)
+}
+
+//redeliver attempts to send a Delivery's event again by executing a
+//webhookcmd.RedeliverCommand.
+//The new Delivery is sent with a 202 since it has not been attempted yet.
+func (a *API) redeliver(w http.ResponseWriter, r *http.Request) {
+	webhookId, ok := a.idFrom(w, r, routeParamWebhookId)
+	if !ok {
+		return
+	}
+
+	deliveryId, ok := a.idFrom(w, r, routeParamDeliveryId)
+	if !ok {
+		return
+	}
+
+	command := &webhookcmd.RedeliverCommand{
+		WebhookId:  webhookId,
+		DeliveryId: deliveryId,
+	}
+
+	delivery, err := a.Bus.ExecuteContext(r.Context(), command)
+	a.webhookCommandResponse(w, delivery, err, http.StatusAccepted)
+}
+
+//getWebhookEntity is a helper method to retrieve a Webhook from a.Webhooks.
+//If false is returned, that indicates the Webhook could not be retrieved -
+//through an actual failure or because it does not exist.
+//A response is sent if false is returned.
+func (a *API) getWebhookEntity(w http.ResponseWriter, r *http.Request) (*webhook.Webhook, bool) {
+	webhookId, ok := a.idFrom(w, r, routeParamWebhookId)
+	if !ok {
+		return nil, ok
+	}
+
+	webhook, err := a.Webhooks.Get(r.Context(), webhookId)
+	if err != nil {
+		//A not found error is sent as a 404 by sendError.
+		a.sendError(w, err, http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return webhook, true
+}
+
+//webhookCommandResponse is a helper method to send the correct response from
+//the result of executing a webhook command.
+func (a *API) webhookCommandResponse(w http.ResponseWriter, result interface{}, err error, okStatus int) {
+	if err != nil {
+		a.sendError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, result)
+	a.sendData(w, result, okStatus)
+}
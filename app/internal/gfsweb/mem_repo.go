@@ -0,0 +1,16 @@
+package gfsweb
+
+import (
+	"io"
+
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/memrepo"
+	"github.com/gogolfing/config"
+)
+
+//CreateMemRepo returns a new, empty memrepo.Repo and an io.Closer that should
+//be called when the repo is no longer needed.
+func CreateMemRepo(_ *config.Config) (*memrepo.Repo, io.Closer, error) {
+	memRepo := memrepo.New()
+
+	return memRepo, memRepo, nil
+}
@@ -1,25 +1,83 @@
 package gfsweb
 
 import (
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey/apikeymem"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/apikey/apikeysql"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/audit"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/audit/auditmem"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/audit/auditsql"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change/changemem"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/change/changesql"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client/clientmem"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/client/clientsql"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/outbox"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/outbox/outboxmem"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/outbox/outboxsql"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/memrepo"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/sqlrepo"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user/usermem"
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/user/usersql"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook/webhookmem"
+	"github.com/AgencyPMG/go-from-scratch/app/internal/data/webhook/webhooksql"
 )
 
 //Repos is a collection of domain type repositories used throughout the application.
 //Passing this collection around is easier than passing all of them individually.
+//
+//Transactor starts transactions that all of the repositories join.
 type Repos struct {
+	Transactor data.Transactor
+
 	Users   user.Repo
 	Clients client.Repo
+	Audit   audit.Repo
+	Outbox  outbox.Repo
+	Changes change.QueryRepo
+
+	Webhooks          webhook.Repo
+	WebhookDeliveries webhook.DeliveryRepo
+
+	APIKeys apikey.Repo
 }
 
 //NewRepos returns a new Repos with each repository created from each domain
 //package's sql repo implementation.
 func NewRepos(sqlRepo *sqlrepo.Repo) *Repos {
 	return &Repos{
-		Users:   usersql.New(sqlRepo),
-		Clients: clientsql.New(sqlRepo),
+		Transactor: sqlRepo,
+		Users:      usersql.New(sqlRepo),
+		Clients:    clientsql.New(sqlRepo),
+		Audit:      auditsql.New(sqlRepo),
+		Outbox:     outboxsql.New(sqlRepo),
+		Changes:    changesql.New(sqlRepo),
+
+		Webhooks:          webhooksql.New(sqlRepo),
+		WebhookDeliveries: webhooksql.NewDeliveryRepo(sqlRepo),
+
+		APIKeys: apikeysql.New(sqlRepo),
+	}
+}
+
+//NewMemRepos returns a new Repos with each repository created from each domain
+//package's memory repo implementation.
+func NewMemRepos(memRepo *memrepo.Repo) *Repos {
+	return &Repos{
+		Transactor: memRepo,
+		Users:      usermem.New(memRepo),
+		Clients:    clientmem.New(memRepo),
+		Audit:      auditmem.New(memRepo),
+		Outbox:     outboxmem.New(memRepo),
+		Changes:    changemem.New(memRepo),
+
+		Webhooks:          webhookmem.New(memRepo),
+		WebhookDeliveries: webhookmem.NewDeliveryRepo(memRepo),
+
+		APIKeys: apikeymem.New(memRepo),
 	}
 }
@@ -2,35 +2,52 @@ package gfsweb
 
 import (
 	"database/sql"
+	"fmt"
 	"io"
+	"strings"
 
 	"github.com/AgencyPMG/go-from-scratch/app/internal/data/storage/sqlrepo"
 	"github.com/gogolfing/config"
 
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 const (
 	ConfigKeyDatabaseURL = "databases.app.url"
 
 	SQLDatasourceName = "postgres"
+
+	SQLiteDatasourceName = "sqlite3"
 )
 
 var sqlrepoDialect = sqlrepo.PostgresqlDialect{}
 
+var sqliteDialect = sqlrepo.SqliteDialect{}
+
+//sqliteURLPrefix is the prefix of database urls that should use SQLite.
+//The rest of the url is the path to the database file or ":memory:".
+const sqliteURLPrefix = "sqlite://"
+
 //CreateSQLRepo returns a new sqlrepo.Repo and an io.Closer that will
 //close the connection to the database.
 //
-//It connects to a Postgresql database at the url specified in config at key ConfigKeyDatabaseURL.
+//It connects to the database at the url specified in config at key ConfigKeyDatabaseURL.
+//Urls starting with "sqlite://" connect to a SQLite database and all others
+//connect to a Postgresql database.
 func CreateSQLRepo(config *config.Config) (*sqlrepo.Repo, io.Closer, error) {
-	db, err := sql.Open(
-		SQLDatasourceName,
-		config.GetString(ConfigKeyDatabaseURL),
-	)
+	url := config.GetString(ConfigKeyDatabaseURL)
+
+	if strings.HasPrefix(url, sqliteURLPrefix) {
+		return createSqliteRepo(strings.TrimPrefix(url, sqliteURLPrefix))
+	}
+
+	db, err := sql.Open(SQLDatasourceName, url)
 	if err != nil {
 		return nil, nil, err
 	}
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, nil, err
 	}
 
@@ -38,3 +55,28 @@ func CreateSQLRepo(config *config.Config) (*sqlrepo.Repo, io.Closer, error) {
 
 	return sqlRepo, db, nil
 }
+
+//createSqliteRepo returns a new sqlrepo.Repo connected to the SQLite database at path.
+//
+//SQLite only supports a single writer, and every connection to ":memory:" is its
+//own database, so the returned Repo uses a single connection.
+func createSqliteRepo(path string) (*sqlrepo.Repo, io.Closer, error) {
+	if path == "" {
+		return nil, nil, fmt.Errorf("gfsweb: missing sqlite database path in %q", ConfigKeyDatabaseURL)
+	}
+
+	db, err := sql.Open(SQLiteDatasourceName, sqlrepo.SqliteDSN(path))
+	if err != nil {
+		return nil, nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	sqlRepo := sqlrepo.New(db, sqliteDialect)
+
+	return sqlRepo, db, nil
+}